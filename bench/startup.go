@@ -0,0 +1,61 @@
+//go:build ignore
+
+// Startup measures how long rye-fyne binaries take to start and evaluate a
+// script and how much memory they use, by running each a number of times:
+//
+//	go run bench/startup.go -n 20 ./rye-fyne-old ./rye-fyne
+//
+// The script, examples/01-hello-world.rye by default, is run with
+// `rye-fyne test`, so it is evaluated with the test driver and show-and-run
+// returns instead of waiting for the window to be closed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"syscall"
+	"time"
+)
+
+func main() {
+	runs := flag.Int("n", 20, "The number of runs of each binary")
+	script := flag.String("script", "examples/01-hello-world.rye", "The script to run")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: go run bench/startup.go [-n runs] [-script file] rye-fyne...")
+		os.Exit(2)
+	}
+	fmt.Printf("%-30s %10s %10s %10s\n", "binary", "median", "min", "max RSS")
+	for _, bin := range flag.Args() {
+		var times []time.Duration
+		var rss int64
+		for range *runs {
+			cmd := exec.Command(bin, "test", *script)
+			start := time.Now()
+			if out, err := cmd.CombinedOutput(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n%s", bin, err, out)
+				os.Exit(1)
+			}
+			times = append(times, time.Since(start))
+			rss = max(rss, maxRSS(cmd.ProcessState))
+		}
+		slices.Sort(times)
+		fmt.Printf("%-30s %10s %10s %7.1f MB\n", bin, times[len(times)/2].Round(100*time.Microsecond), times[0].Round(100*time.Microsecond), float64(rss)/(1<<20))
+	}
+}
+
+// maxRSS returns the maximum resident set size of a process in bytes.
+func maxRSS(ps *os.ProcessState) int64 {
+	usage, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	if runtime.GOOS == "darwin" {
+		return usage.Maxrss
+	}
+	return usage.Maxrss * 1024
+}
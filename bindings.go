@@ -0,0 +1,448 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"weak"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// boundValue is what fyne's Item[T] and List[T] bindings have in common.
+type boundValue[T any] interface {
+	binding.DataItem
+	Get() (T, error)
+	Set(T) error
+}
+
+// findVar returns the context holding the Rye variable named by word,
+// looking in the current context and its parents.
+func findVar(ps *env.ProgramState, word env.Word) (*env.RyeCtx, error) {
+	name := ps.Idx.GetWord(word.Index)
+	for ctx := ps.Ctx; ctx != nil; ctx = ctx.Parent {
+		if _, ok := ctx.GetCurrent(word.Index); !ok {
+			continue
+		}
+		if !ctx.IsVariable(word.Index) {
+			return nil, errors.New("word '" + name + "' is not a variable. Use 'var' to declare it as variable first.")
+		}
+		return ctx, nil
+	}
+	return nil, errors.New("word '" + name + "' not found")
+}
+
+// varObserver is the observer of a variable that passes its new values to
+// Go. Rye has no way to remove observers, so there is one per variable, and
+// the functions it calls are added and removed.
+type varObserver struct {
+	mu   sync.Mutex
+	next int
+	fns  map[int]func(ps *env.ProgramState, val env.Object)
+}
+
+// varObservers are the observers of the variables of each context, by the
+// index of the variable. varObserversMu guards making them.
+var (
+	varObserversMu sync.Mutex
+	varObservers   objectMap[map[int]*varObserver]
+)
+
+// observeVar calls fn with the new value each time the variable idx of ctx
+// changes, the same way as observers registered with on-change are called,
+// until remove is called.
+func observeVar(ctx *env.RyeCtx, idx int, fn func(ps *env.ProgramState, val env.Object)) (remove func()) {
+	varObserversMu.Lock()
+	vars, ok := varObservers.load(ctx)
+	if !ok {
+		vars = map[int]*varObserver{}
+		varObservers.store(ctx, vars)
+	}
+	o := vars[idx]
+	if o == nil {
+		o = &varObserver{fns: map[int]func(*env.ProgramState, env.Object){}}
+		vars[idx] = o
+		observer := env.NewBuiltin(func(ps *env.ProgramState, arg0, arg1, arg2, arg3, arg4 env.Object) env.Object {
+			val, _ := ctx.GetCurrent(idx)
+			o.mu.Lock()
+			fns := make([]func(*env.ProgramState, env.Object), 0, len(o.fns))
+			for _, fn := range o.fns {
+				fns = append(fns, fn)
+			}
+			o.mu.Unlock()
+			for _, fn := range fns {
+				fn(ps, val)
+			}
+			return val
+		}, 0, false, false, "Passes the new value of a variable to Go.")
+		ctx.AddObserver(idx, *env.NewBlock(*env.NewTSeries([]env.Object{*observer})))
+	}
+	varObserversMu.Unlock()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	id := o.next
+	o.next++
+	o.fns[id] = fn
+	return func() {
+		o.mu.Lock()
+		delete(o.fns, id)
+		o.mu.Unlock()
+	}
+}
+
+// boundItem and boundList are the bindings bindVar returns. The observer of
+// the variable holds them weakly, so a binding that is dropped, with the
+// widgets listening to it, is collected, and its function is removed from
+// the observer.
+type boundItem[T any] struct{ binding.Item[T] }
+
+type boundList[T any] struct{ binding.List[T] }
+
+// bindVar keeps the binding b in sync with the Rye variable named by arg.
+//
+// Changes of the variable made from Rye are passed to b by an observer, like
+// the ones registered with on-change. Changes made through b, like the user
+// editing an entry, are written back to the variable and trigger its
+// observers. Fyne bindings notify their listeners with fyne.Do, so widgets
+// are always updated on the main goroutine.
+func bindVar[T any, B any, PB interface {
+	*B
+	boundValue[T]
+}](ps *env.ProgramState, arg env.Object, b PB, kind string,
+	fromRye func(ps *env.ProgramState, obj env.Object) (T, error),
+	toRye func(ps *env.ProgramState, cur env.Object, v T) env.Object,
+) env.Object {
+	word, ok := arg.(env.Word)
+	if !ok {
+		ps.FailureFlag = true
+		return env.NewError("expected word naming a variable, but got " + objectType(ps, arg))
+	}
+	ctx, err := findVar(ps, word)
+	if err != nil {
+		ps.FailureFlag = true
+		return env.NewError(err.Error())
+	}
+	name := ps.Idx.GetWord(word.Index)
+
+	cur, _ := ctx.GetCurrent(word.Index)
+	v, err := fromRye(ps, cur)
+	if err != nil {
+		ps.FailureFlag = true
+		return env.NewError("variable '" + name + "': " + err.Error())
+	}
+	if err := b.Set(v); err != nil {
+		ps.FailureFlag = true
+		return goErrorToRye(ps, err)
+	}
+
+	wb := weak.Make((*B)(b))
+	remove := observeVar(ctx, word.Index, func(ps *env.ProgramState, val env.Object) {
+		b := PB(wb.Value())
+		if b == nil {
+			return
+		}
+		v, err := fromRye(ps, val)
+		if err == nil {
+			err = b.Set(v)
+		}
+		if err != nil {
+			writeBindingError(name, err)
+		}
+	})
+	runtime.AddCleanup((*B)(b), func(remove func()) { remove() }, remove)
+
+	base := forkProgramState(ps)
+	b.AddListener(binding.NewDataListener(func() {
+		v, err := b.Get()
+		if err != nil {
+			writeBindingError(name, err)
+			return
+		}
+		old, _ := ctx.GetCurrent(word.Index)
+		val := toRye(base, old, v)
+		if old.Equal(val) {
+			return
+		}
+		switch res, typ := ctx.ModWithInfo(word.Index, val); res {
+		case env.ModOK:
+			evaldo.TriggerObservers(forkProgramState(base), ctx, word.Index, old, val)
+		case env.ModErrTypeMismatch:
+			writeBindingError(name, errors.New("can't change type of variable from "+base.Idx.GetWord(int(typ))+" to "+base.Idx.GetWord(int(val.Type()))))
+		default:
+			writeBindingError(name, errors.New("can't set variable"))
+		}
+	}))
+
+	return *env.NewNative(ps.Idx, b, kind)
+}
+
+func writeBindingError(name string, err error) {
+	fmt.Fprintf(os.Stderr, "Error in binding of '%s': %s\n", name, err)
+}
+
+func stringFromRye(ps *env.ProgramState, obj env.Object) (string, error) {
+	s, ok := obj.(env.String)
+	if !ok {
+		return "", errors.New("expected string, but got " + objectType(ps, obj))
+	}
+	return s.Value, nil
+}
+
+func boolFromRye(ps *env.ProgramState, obj env.Object) (bool, error) {
+	b, ok := obj.(env.Boolean)
+	if !ok {
+		return false, errors.New("expected boolean, but got " + objectType(ps, obj))
+	}
+	return b.Value, nil
+}
+
+func stringsFromRye(ps *env.ProgramState, obj env.Object) ([]string, error) {
+	var items []env.Object
+	switch x := obj.(type) {
+	case env.Block:
+		items = x.Series.GetAll()
+	case env.List:
+		for _, v := range x.Data {
+			items = append(items, env.ToRyeValue(v))
+		}
+	default:
+		return nil, errors.New("expected block or list of strings, but got " + objectType(ps, obj))
+	}
+	strs := make([]string, len(items))
+	for i, item := range items {
+		s, err := stringFromRye(ps, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// stringsToRye returns strs as a block, or as a list if the variable holds
+// one.
+func stringsToRye(ps *env.ProgramState, cur env.Object, strs []string) env.Object {
+	if _, ok := cur.(env.List); ok {
+		data := make([]any, len(strs))
+		for i, s := range strs {
+			data[i] = s
+		}
+		return *env.NewList(data)
+	}
+	items := make([]env.Object, len(strs))
+	for i, s := range strs {
+		items[i] = *env.NewString(s)
+	}
+	blk := env.NewBlock(*env.NewTSeries(items))
+	if b, ok := cur.(env.Block); ok {
+		blk.Mode = b.Mode
+	}
+	return *blk
+}
+
+func stringToRye(ps *env.ProgramState, cur env.Object, v string) env.Object {
+	return *env.NewString(v)
+}
+
+func intValueFromRye(ps *env.ProgramState, obj env.Object) (int, error) {
+	v, ok, err := intFromRye[int](obj, "int")
+	if !ok {
+		return 0, errors.New("expected integer, but got " + objectType(ps, obj))
+	}
+	return v, err
+}
+
+func intToRye(ps *env.ProgramState, cur env.Object, v int) env.Object {
+	return *env.NewInteger(int64(v))
+}
+
+func floatValueFromRye(ps *env.ProgramState, obj env.Object) (float64, error) {
+	v, ok, err := floatFromRye[float64](obj, "float64")
+	if !ok {
+		return 0, errors.New("expected decimal, but got " + objectType(ps, obj))
+	}
+	return v, err
+}
+
+func floatToRye(ps *env.ProgramState, cur env.Object, v float64) env.Object {
+	return *env.NewDecimal(v)
+}
+
+func boolToRye(ps *env.ProgramState, cur env.Object, v bool) env.Object {
+	return *env.NewBoolean(v)
+}
+
+func untypedFromRye(ps *env.ProgramState, obj env.Object) (any, error) {
+	return obj, nil
+}
+
+func untypedToRye(ps *env.ProgramState, cur env.Object, v any) env.Object {
+	if obj, ok := v.(env.Object); ok {
+		return obj
+	}
+	if nat, ok := autoToNative(ps, v); ok {
+		return nat
+	}
+	return *env.NewNative(ps.Idx, v, "go(any)")
+}
+
+// boundType gets and sets the values of the bindings holding a T. ok is
+// false if b doesn't hold a T.
+type boundType struct {
+	get func(ps *env.ProgramState, b any) (_ env.Object, ok bool, _ error)
+	set func(ps *env.ProgramState, b any, obj env.Object) (ok bool, _ error)
+}
+
+func newBoundType[T any](
+	fromRye func(ps *env.ProgramState, obj env.Object) (T, error),
+	toRye func(ps *env.ProgramState, cur env.Object, v T) env.Object,
+) boundType {
+	return boundType{
+		get: func(ps *env.ProgramState, b any) (env.Object, bool, error) {
+			bv, ok := b.(boundValue[T])
+			if !ok {
+				return nil, false, nil
+			}
+			v, err := bv.Get()
+			if err != nil {
+				return nil, true, err
+			}
+			return toRye(ps, nil, v), true, nil
+		},
+		set: func(ps *env.ProgramState, b any, obj env.Object) (bool, error) {
+			bv, ok := b.(boundValue[T])
+			if !ok {
+				return false, nil
+			}
+			v, err := fromRye(ps, obj)
+			if err != nil {
+				return true, err
+			}
+			return true, bv.Set(v)
+		},
+	}
+}
+
+// boundTypes are the types of the values of the bindings made by the
+// bind-*-var builtins, which value? and value! can convert.
+var boundTypes = []boundType{
+	newBoundType(stringFromRye, stringToRye),
+	newBoundType(intValueFromRye, intToRye),
+	newBoundType(floatValueFromRye, floatToRye),
+	newBoundType(boolFromRye, boolToRye),
+	newBoundType(untypedFromRye, untypedToRye),
+	newBoundType(stringsFromRye, stringsToRye),
+}
+
+func init() {
+	m := builtins_fyne_data_binding
+	m["bind-string-var"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns a binding.String kept in sync with the Rye variable holding a string, named by the word.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return bindVar(ps, args[0], &boundItem[string]{binding.NewString()}, "go(binding/DataItem)", stringFromRye, stringToRye)
+		},
+	}
+	m["bind-int-var"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns a binding.Int kept in sync with the Rye variable holding an integer, named by the word.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return bindVar(ps, args[0], &boundItem[int]{binding.NewInt()}, "go(binding/DataItem)", intValueFromRye, intToRye)
+		},
+	}
+	m["bind-float-var"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns a binding.Float kept in sync with the Rye variable holding a decimal, named by the word.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return bindVar(ps, args[0], &boundItem[float64]{binding.NewFloat()}, "go(binding/DataItem)", floatValueFromRye, floatToRye)
+		},
+	}
+	m["bind-bool-var"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns a binding.Bool kept in sync with the Rye variable holding a boolean, named by the word.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return bindVar(ps, args[0], &boundItem[bool]{binding.NewBool()}, "go(binding/DataItem)", boolFromRye, boolToRye)
+		},
+	}
+	m["bind-list-var"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns a binding.StringList kept in sync with the Rye variable holding a block or list of strings, named by the word. Only strings are supported as items.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return bindVar(ps, args[0], &boundList[string]{binding.NewStringList()}, "go(binding/DataList)", stringsFromRye, stringsToRye)
+		},
+	}
+	m["bind-untyped-var"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns a binding.Untyped holding the Rye value of the variable named by the word, kept in sync with it.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			// Rye values can't be compared with ==, which the default
+			// comparator of binding.NewUntyped uses.
+			b := binding.NewItem(func(a, b any) bool {
+				x, ok1 := a.(env.Object)
+				y, ok2 := b.(env.Object)
+				if !ok1 || !ok2 {
+					return false
+				}
+				return x.Equal(y)
+			})
+			return bindVar(ps, args[0], &boundItem[any]{b}, "go(binding/DataItem)", untypedFromRye, untypedToRye)
+		},
+	}
+
+	// The generated code has no methods for the generic Item and List types,
+	// so value? and value! are added to the kinds of the bind-*-var bindings,
+	// next to the generated add-listener and remove-listener. They aren't
+	// named get and set, as those words are Rye builtins, found before the
+	// methods of the kind.
+	for _, kind := range []string{"go(binding/DataItem)", "go(binding/DataList)"} {
+		m[kind+"//value?"] = &env.VarBuiltin{
+			Argsn: 1,
+			Doc:   "Returns the value of the binding.",
+			Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+				nat, ok := args[0].(env.Native)
+				if !ok {
+					ps.FailureFlag = true
+					return env.NewError("expected native binding, but got " + objectType(ps, args[0]))
+				}
+				for _, t := range boundTypes {
+					if v, ok, err := t.get(ps, nat.Value); ok {
+						if err != nil {
+							ps.FailureFlag = true
+							return goErrorToRye(ps, err)
+						}
+						return v
+					}
+				}
+				ps.FailureFlag = true
+				return env.NewError("can't get the value of " + objectType(ps, args[0]))
+			},
+		}
+		m[kind+"//value!"] = &env.VarBuiltin{
+			Argsn: 2,
+			Doc:   "Sets the value of the binding.",
+			Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+				nat, ok := args[0].(env.Native)
+				if !ok {
+					ps.FailureFlag = true
+					return env.NewError("expected native binding, but got " + objectType(ps, args[0]))
+				}
+				for _, t := range boundTypes {
+					if ok, err := t.set(ps, nat.Value, args[1]); ok {
+						if err != nil {
+							ps.FailureFlag = true
+							return goErrorToRye(ps, err)
+						}
+						return args[0]
+					}
+				}
+				ps.FailureFlag = true
+				return env.NewError("can't set the value of " + objectType(ps, args[0]))
+			},
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/refaktor/rye/env"
+)
+
+func TestBoundVarValue(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		var 'name "World"
+		var 'names { "a" }
+		b: binding/bind-string-var 'name
+		bs: binding/bind-list-var 'names
+		old: b .value?
+		b .value! "Rye"
+		bs .value! { "b" "c" }
+		new: b .value?
+	`)
+	for word, want := range map[string]string{"old": "World", "new": "Rye"} {
+		obj, _ := ps.Ctx.Get(ps.Idx.IndexWord(word))
+		if s, ok := obj.(env.String); !ok || s.Value != want {
+			t.Errorf("got %s %s, want %q", word, objectType(ps, obj), want)
+		}
+	}
+	strs, err := testValue[binding.StringList](t, ps, "bs").Get()
+	if err != nil || len(strs) != 2 || strs[0] != "b" || strs[1] != "c" {
+		t.Errorf("got list %q, %v", strs, err)
+	}
+}
+
+// waitFor waits for cond, as bindings notify their listeners through the
+// queue of fyne.Do.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; !cond(); i++ {
+		if i == 100 {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBoundVarSync(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		var 'name "World"
+		var 'changes 0
+		on-change 'name { changes:: changes + 1 }
+		b: binding/bind-string-var 'name
+		change! "Rye" 'name
+	`)
+	b := testValue[binding.String](t, ps, "b")
+	if s, _ := b.Get(); s != "Rye" {
+		t.Errorf("got binding %q after changing the variable, want \"Rye\"", s)
+	}
+
+	changes := func() int64 {
+		obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("changes"))
+		return obj.(env.Integer).Value
+	}
+	before := changes()
+	if err := b.Set("Go"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the variable", func() bool {
+		obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("name"))
+		return obj.(env.String).Value == "Go"
+	})
+	waitFor(t, "on-change", func() bool { return changes() == before+1 })
+}
+
+func TestBoundVarObserverRemoved(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		var 'name "World"
+		loop 20 { binding/bind-string-var 'name }
+		b: binding/bind-string-var 'name
+	`)
+	observers := func() int {
+		vars, _ := varObservers.load(ps.Ctx)
+		o := vars[ps.Idx.IndexWord("name")]
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		return len(o.fns)
+	}
+	for i := 0; observers() > 1; i++ {
+		if i == 100 {
+			t.Fatalf("got %d observers of the dropped bindings, want 1", observers())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	evalTest(t, ps, `change! "Rye" 'name`)
+	if s, _ := testValue[binding.String](t, ps, "b").Get(); s != "Rye" {
+		t.Errorf("got binding %q after changing the variable, want \"Rye\"", s)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/template"
+
+	"github.com/refaktor/rye/env"
+)
+
+// `rye-fyne build [flags] [dir]` makes a single binary of the app in dir:
+// the Rye files and assets of the directory are embedded with embed.FS into
+// a copy of rye-fyne that runs main.rye, with the Fyne app metadata set from
+// the flags and the FyneApp.toml of the directory. It builds the rye-fyne
+// sources with the Go toolchain, so go has to be on the PATH. The sources are
+// those in -src, the checkout in the current directory, or those of the
+// rye-fyne version that is running.
+
+// modulePath is the module of the rye-fyne sources.
+const modulePath = "github.com/refaktor/rye-fyne"
+
+// appDir is the directory the app is embedded from in the build directory.
+const appDir = "ryeapp"
+
+// buildOptions are the flags of the build command.
+type buildOptions struct {
+	output  string
+	src     string
+	name    string
+	id      string
+	version string
+	build   int
+	icon    string
+	release bool
+	// custom and migrations are only set by the manifest.
+	custom     map[string]string
+	migrations map[string]bool
+}
+
+func init() {
+	commands["build"] = runBuild
+}
+
+// runBuild is the build command.
+func runBuild(regfn func(*env.ProgramState) error, args []string) int {
+	var opts buildOptions
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	flags.StringVar(&opts.output, "o", "", "The binary to write (default: the app name)")
+	flags.StringVar(&opts.src, "src", "", "The directory of the rye-fyne sources")
+	flags.StringVar(&opts.name, "name", "", "The app name (default: the directory name)")
+	flags.StringVar(&opts.id, "id", "", "The app ID, like com.example.app")
+	flags.StringVar(&opts.version, "version", "0.0.1", "The app version")
+	flags.IntVar(&opts.build, "build", 1, "The build number")
+	flags.StringVar(&opts.icon, "icon", "", "The app icon (default: Icon.png in the directory, if any)")
+	flags.BoolVar(&opts.release, "release", false, "Build for release")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	dir := "."
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		dir = flags.Arg(0)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: rye-fyne build [flags] [dir]")
+		return 2
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if err := opts.useManifest(dir, set); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := buildApp(dir, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// useManifest sets the options that are not set with flags from the
+// manifest in dir, if there is one.
+func (opts *buildOptions) useManifest(dir string, set map[string]bool) error {
+	m, err := loadManifest(dir)
+	if m == nil {
+		return err
+	}
+	d := m.Details
+	if !set["name"] && d.Name != "" {
+		opts.name = d.Name
+	}
+	if !set["id"] && d.ID != "" {
+		opts.id = d.ID
+	}
+	if !set["version"] && d.Version != "" {
+		opts.version = d.Version
+	}
+	if !set["build"] && d.Build != 0 {
+		opts.build = d.Build
+	}
+	if !set["icon"] && d.Icon != "" {
+		opts.icon = filepath.Join(dir, d.Icon)
+	}
+	opts.custom = m.custom(opts.release)
+	opts.migrations = m.Migrations
+	return nil
+}
+
+func buildApp(dir string, opts buildOptions) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, embeddedMain)); err != nil {
+		return fmt.Errorf("no %s in %s", embeddedMain, dir)
+	}
+	if opts.name == "" {
+		opts.name = filepath.Base(dir)
+	}
+	if opts.output == "" {
+		opts.output = opts.name
+		goos := os.Getenv("GOOS")
+		if goos == "" {
+			goos = runtime.GOOS
+		}
+		if goos == "windows" {
+			opts.output += ".exe"
+		}
+	}
+	output, err := filepath.Abs(opts.output)
+	if err != nil {
+		return err
+	}
+	if opts.icon == "" {
+		if _, err := os.Stat(filepath.Join(dir, "Icon.png")); err == nil {
+			opts.icon = filepath.Join(dir, "Icon.png")
+		}
+	}
+	src, err := sourceDir(opts.src)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp("", "rye-fyne-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := copySources(src, tmp); err != nil {
+		return err
+	}
+	if err := copyApp(dir, filepath.Join(tmp, appDir), output); err != nil {
+		return err
+	}
+	iconFile := ""
+	if opts.icon != "" {
+		iconFile = "appicon" + filepath.Ext(opts.icon)
+		if err := copyFile(opts.icon, filepath.Join(tmp, iconFile)); err != nil {
+			return err
+		}
+	}
+	if err := writeEmbedFile(filepath.Join(tmp, "embedded_app.go"), opts, iconFile); err != nil {
+		return err
+	}
+
+	fmt.Printf("building %s from %s\n", output, dir)
+	cmd := exec.Command("go", "build", "-o", output, ".")
+	cmd.Dir = tmp
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// sourceDir returns the directory of the rye-fyne sources: dir if it isn't
+// empty, the checkout the current directory is in, or the module of the
+// running version.
+func sourceDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, checkModule(dir)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for d := wd; ; d = filepath.Dir(d) {
+		if checkModule(d) == nil {
+			return d, nil
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path != modulePath || info.Main.Version == "" || info.Main.Version == "(devel)" || strings.HasSuffix(info.Main.Version, "+dirty") {
+		return "", errors.New("rye-fyne sources not found, use -src to give their directory")
+	}
+	out, err := exec.Command("go", "mod", "download", "-json", modulePath+"@"+info.Main.Version).Output()
+	if err != nil {
+		return "", fmt.Errorf("downloading %s@%s: %w", modulePath, info.Main.Version, err)
+	}
+	var mod struct{ Dir string }
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return "", err
+	}
+	return mod.Dir, nil
+}
+
+// checkModule returns an error if dir is not the rye-fyne module.
+func checkModule(dir string) error {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if mod, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "module "); ok {
+			if strings.TrimSpace(mod) == modulePath {
+				return nil
+			}
+			break
+		}
+	}
+	return fmt.Errorf("%s is not the %s module", dir, modulePath)
+}
+
+// copySources copies the main package of rye-fyne and its go.mod and go.sum.
+func copySources(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(name == "go.mod" || name == "go.sum" || strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")) {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyApp copies the files of the app, except hidden ones, those of Go
+// modules and the binary being built.
+func copyApp(src, dst, output string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != src && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p == output || !d.IsDir() && isGoFile(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		return copyFile(p, filepath.Join(dst, rel))
+	})
+}
+
+// isGoFile reports whether name is a file of a Go module, or of the lrye
+// tool, like the ones of an app made with Rye's build tools. They are not
+// embedded, as go:embed doesn't embed other modules.
+func isGoFile(name string) bool {
+	return name == "go.mod" || name == "go.sum" || strings.HasSuffix(name, ".go") || strings.HasPrefix(name, "lrye.")
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+var embedTemplate = template.Must(template.New("embed").Parse(`// Code generated by rye-fyne build. DO NOT EDIT.
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+
+	"fyne.io/fyne/v2"
+)
+
+//go:embed all:{{.Dir}}
+var embeddedAppFiles embed.FS
+{{if .Icon}}
+//go:embed {{.Icon}}
+var embeddedAppIcon []byte
+{{end}}
+func init() {
+	files, err := fs.Sub(embeddedAppFiles, {{printf "%q" .Dir}})
+	if err != nil {
+		panic(err)
+	}
+	embedApp(files, fyne.AppMetadata{
+		ID:      {{printf "%q" .ID}},
+		Name:    {{printf "%q" .Name}},
+		Version: {{printf "%q" .Version}},
+		Build:   {{.Build}},
+{{- if .Icon}}
+		Icon:    fyne.NewStaticResource({{printf "%q" .Icon}}, embeddedAppIcon),
+{{- end}}
+		Release: {{.Release}},
+{{- if .Custom}}
+		Custom:  {{printf "%#v" .Custom}},
+{{- end}}
+{{- if .Migrations}}
+		Migrations: {{printf "%#v" .Migrations}},
+{{- end}}
+	})
+}
+`))
+
+// writeEmbedFile writes the Go file that embeds the app.
+func writeEmbedFile(file string, opts buildOptions, icon string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = embedTemplate.Execute(f, map[string]any{
+		"Dir":        appDir,
+		"Icon":       icon,
+		"ID":         opts.id,
+		"Name":       opts.name,
+		"Version":    opts.version,
+		"Build":      opts.build,
+		"Release":    opts.release,
+		"Custom":     opts.custom,
+		"Migrations": opts.migrations,
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// The hello-world-build example has the go.mod and Go files of Rye's build
+// tools next to main.rye, which must not be embedded. Building needs cgo and
+// the OpenGL headers and takes a while, so it only runs with
+// RYE_FYNE_BUILD_TEST=1.
+func TestBuildExample(t *testing.T) {
+	if os.Getenv("RYE_FYNE_BUILD_TEST") != "1" {
+		t.Skip("set RYE_FYNE_BUILD_TEST=1 to build an app")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	out := filepath.Join(t.TempDir(), "hello")
+	if err := buildApp("examples/hello-world-build", buildOptions{output: out, src: "."}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyAppSkipsGoFiles(t *testing.T) {
+	dst := t.TempDir()
+	if err := copyApp("examples/hello-world-build", dst, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"go.mod", "go.sum", "fyne_metadata_init.go", "lrye.mod", "lrye.files"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err == nil {
+			t.Errorf("%s was copied", name)
+		}
+	}
+	for _, name := range []string{"main.rye", "FyneApp.toml", "Icon.png"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/refaktor/rye/env"
+)
+
+// baseBuiltins holds hand-written builtins that are registered into the base
+// context next to nil, is-nil and import\go. Files add their entries from
+// init.
+var baseBuiltins = map[string]*env.VarBuiltin{}
+
+// overrideBuiltins replace builtins of the generated code, like import\go.
+// They are registered after all others by withCommands.
+var overrideBuiltins = map[string]*env.VarBuiltin{}
+
+// registerOverrides registers overrideBuiltins into the base context of ps.
+func registerOverrides(ps *env.ProgramState) {
+	for name, b := range overrideBuiltins {
+		idx := ps.Idx.IndexWord(name)
+		ps.Ctx.Unset(idx, ps.Idx)
+		ps.Ctx.Set(idx, *env.NewVarBuiltin(b.Fn, b.Argsn, b.AcceptFailure, b.Pure, b.Doc+" (rye-fyne)"))
+	}
+}
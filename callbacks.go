@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// forkProgramState returns a shallow copy of ps with its evaluation
+// state reset.
+//
+// Rye functions converted to Go callbacks are invoked by Fyne from
+// arbitrary goroutines, possibly while the main program or a goroutine
+// started with `go` is still evaluating. Every callback invocation runs
+// on its own fork, so results, failure flags and the current series
+// are never shared between concurrent evaluations. The word contexts
+// are shared, as they are with `go`.
+func forkProgramState(ps *env.ProgramState) *env.ProgramState {
+	psX := *ps
+	psX.Res = nil
+	psX.Inj = nil
+	psX.Injnow = false
+	psX.ReturnFlag = false
+	psX.ErrorFlag = false
+	psX.FailureFlag = false
+	psX.ForcedResult = nil
+	psX.SkipFlag = false
+	psX.InErrHandler = false
+	psX.DeferBlocks = nil
+	psX.ContextStack = nil
+	psX.Stack = env.NewEyrStack()
+	return &psX
+}
+
+// callbackErrorSink decides where errors of Rye callbacks go. By default
+// they are written to stderr. With dialog set they are also shown in an
+// error dialog, with handler set they are passed to that Rye function
+// instead.
+type callbackErrorSink struct {
+	dialog  bool
+	handler *env.Function
+}
+
+var callbackErrors atomic.Pointer[callbackErrorSink]
+
+func init() {
+	callbackErrors.Store(&callbackErrorSink{})
+
+	builtins_fyne["on-callback-error"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Sets where errors in callbacks are reported: 'stderr, 'dialog or a function accepting the error.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			switch arg := args[0].(type) {
+			case env.Function:
+				if arg.Argsn != 1 {
+					ps.FailureFlag = true
+					return env.NewError(fmt.Sprintf("expected function with 1 argument, but got %d", arg.Argsn))
+				}
+				callbackErrors.Store(&callbackErrorSink{handler: &arg})
+				return arg
+			case env.Word:
+				switch ps.Idx.GetWord(arg.Index) {
+				case "stderr":
+					callbackErrors.Store(&callbackErrorSink{})
+					return arg
+				case "dialog":
+					callbackErrors.Store(&callbackErrorSink{dialog: true})
+					return arg
+				}
+			}
+			ps.FailureFlag = true
+			return env.NewError("expected 'stderr, 'dialog or function, but got " + objectType(ps, args[0]))
+		},
+	}
+}
+
+// reportCallbackError passes an error that occurred while calling the Rye
+// function fn from Go to the current callback error sink. If the function
+// itself failed, ps.Res holds its error.
+func reportCallbackError(ps *env.ProgramState, fn env.Function, err error) {
+	e, ok := ps.Res.(*env.Error)
+	if !ok {
+		e = goErrorToRye(ps, err)
+	}
+	sink := callbackErrors.Load()
+	if sink.handler != nil {
+		psH := forkProgramState(ps)
+		evaldo.CallFunctionArgsN(*sink.handler, psH, nil, *e)
+		if !psH.ErrorFlag && !psH.FailureFlag {
+			return
+		}
+		writeCallbackError(ps, fn, e)
+		fmt.Fprintln(os.Stderr, "Error in callback error handler:")
+		fmt.Fprintln(os.Stderr, psH.Res.Print(*ps.Idx))
+		return
+	}
+	writeCallbackError(ps, fn, e)
+	if sink.dialog {
+		showCallbackErrorDialog(e)
+	}
+}
+
+// writeCallbackError writes e to stderr, followed by the callback and the
+// place where it was passed to Go.
+func writeCallbackError(ps *env.ProgramState, fn env.Function, e *env.Error) {
+	var b strings.Builder
+	b.WriteString(e.Print(*ps.Idx))
+	b.WriteString("\n  in callback " + shortDump(fn, ps.Idx))
+	if fn.Body.FileName != "" {
+		fmt.Fprintf(&b, " at %s:%d", fn.Body.FileName, fn.Body.Line)
+	}
+	b.WriteString("\n  passed to Go")
+	if ps.BlockFile != "" {
+		fmt.Fprintf(&b, " in block at %s:%d", ps.BlockFile, ps.BlockLine)
+	}
+	b.WriteString("\n    ")
+	pos := ps.Ser.Pos()
+	for i := max(0, pos-6); i < min(ps.Ser.Len(), pos+3); i++ {
+		if i == pos {
+			b.WriteString("<here> ")
+		}
+		if obj := ps.Ser.Get(i); obj != nil {
+			b.WriteString(shortDump(obj, ps.Idx) + " ")
+		}
+	}
+	fmt.Fprintln(os.Stderr, b.String())
+}
+
+func shortDump(obj env.Object, idxs *env.Idxs) string {
+	s := obj.Dump(*idxs)
+	if len(s) > 40 {
+		s = s[:37] + "..."
+	}
+	return s
+}
+
+// showCallbackErrorDialog shows e in an error dialog on the most recently
+// opened window of the app.
+func showCallbackErrorDialog(e *env.Error) {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return
+	}
+	fyne.Do(func() {
+		windows := app.Driver().AllWindows()
+		if len(windows) == 0 {
+			return
+		}
+		dialog.ShowError(errors.New(e.Message), windows[len(windows)-1])
+	})
+}
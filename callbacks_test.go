@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// testRegister registers the builtins main registers.
+func testRegister(ps *env.ProgramState) error {
+	registerMethods(ps)
+	evaldo.RegisterVarBuiltins2(baseBuiltins, ps, "rye-fyne")
+	registerOverrides(ps)
+	return nil
+}
+
+// testProgramState returns a program state with the builtins main
+// registers, and a test app.
+func testProgramState(t *testing.T) *env.ProgramState {
+	t.Helper()
+	test.NewTempApp(t)
+	ps, err := newProgramState(testRegister, "test.rye", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
+
+// evalTest evaluates a script in ps, failing the test on errors.
+func evalTest(t *testing.T, ps *env.ProgramState, source string) *env.ProgramState {
+	t.Helper()
+	ps, err := evalSource(ps, "test.rye", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
+
+// testValue returns the value of a word of the script.
+func testValue[T any](t *testing.T, ps *env.ProgramState, word string) T {
+	t.Helper()
+	obj, ok := ps.Ctx.Get(ps.Idx.IndexWord(word))
+	if !ok {
+		t.Fatalf("%s is not set", word)
+	}
+	nat, ok := obj.(env.Native)
+	if !ok {
+		t.Fatalf("%s is %s, not a native", word, objectType(ps, obj))
+	}
+	v, ok := nat.Value.(T)
+	if !ok {
+		t.Fatalf("%s is %s", word, objectType(ps, obj))
+	}
+	return v
+}
+
+// Fyne calls callbacks from its own goroutines, so a callback can run while
+// a goroutine of the script, or another callback, is evaluating. Run with
+// -race.
+func TestCallbacksWhileGoroutineRuns(t *testing.T) {
+	const n = 500
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		taps: channel 1000
+		done: channel 1
+		b: widget/button "Tap" fn { } { taps .Send 1 }
+		tapped: b .on-tapped?
+		go does {
+			loop 500 { tapped }
+			done .Send 1
+		}
+	`)
+	b := testValue[*widget.Button](t, ps, "b")
+	for range n {
+		test.Tap(b)
+	}
+	<-testValue[chan *env.Object](t, ps, "done")
+	if taps := len(testValue[chan *env.Object](t, ps, "taps")); taps != 2*n {
+		t.Errorf("got %d taps, want %d", taps, 2*n)
+	}
+}
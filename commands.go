@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/refaktor/rye/contrib"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+	"github.com/refaktor/rye/loader"
+)
+
+// commands are the subcommands of rye-fyne, like `rye-fyne test`. They are
+// run instead of the Rye runner when their name is the first argument, get
+// the remaining arguments and return the exit code.
+var commands = map[string]func(regfn func(*env.ProgramState) error, args []string) int{}
+
+// withCommands runs the embedded app of a binary made by `rye-fyne build`,
+// the subcommand given on the command line, or the file given in -watch
+// mode, and exits. Otherwise it returns regfn for the Rye runner. The
+// metadata of the app is set from the FyneApp.toml next to the script first,
+// and modules are required relative to the script.
+func withCommands(regfn func(*env.ProgramState) error) func(*env.ProgramState) error {
+	regfn = withOverrides(regfn)
+	flag.Parse()
+	addInspectShortcut()
+	addRepl()
+	stopThemesOnQuit()
+	if embeddedApp != nil {
+		os.Exit(runEmbedded(regfn))
+	}
+	args := flag.Args()
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			os.Exit(cmd(regfn, args[1:]))
+		}
+	}
+	dir := "."
+	if len(args) > 0 {
+		dir = scriptDir(args[0])
+	}
+	if err := applyManifest(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	moduleDir = dir
+	if watchMode && len(args) > 0 {
+		os.Exit(runWatch(regfn, args[0]))
+	}
+	return regfn
+}
+
+// withOverrides returns regfn registering overrideBuiltins after the
+// builtins it registers.
+func withOverrides(regfn func(*env.ProgramState) error) func(*env.ProgramState) error {
+	return func(ps *env.ProgramState) error {
+		if err := regfn(ps); err != nil {
+			return err
+		}
+		registerOverrides(ps)
+		return nil
+	}
+}
+
+// newProgramState returns a program state with the Rye and rye-fyne
+// builtins, like the one the Rye runner evaluates a file in. If idx is not
+// nil, the words are indexed in it, so values from an earlier program state
+// with the same index can be used.
+func newProgramState(regfn func(*env.ProgramState) error, file string, idx *env.Idxs) (*env.ProgramState, error) {
+	ps := env.NewProgramStateNEW()
+	if idx != nil {
+		ps.Idx = idx
+	}
+	ps.ScriptPath = file
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+	ps.WorkingPath = wd
+	evaldo.RegisterBuiltins(ps)
+	evaldo.RegisterVarBuiltins(ps)
+	contrib.RegisterBuiltins(ps, &evaldo.BuiltinNames)
+	if err := regfn(ps); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// evalFile evaluates a Rye file in a new context of ps, which it returns.
+func evalFile(ps *env.ProgramState, file string) (*env.ProgramState, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return evalSource(ps, file, string(content))
+}
+
+// evalSource evaluates the Rye source of a file in a new context of ps,
+// which it returns.
+func evalSource(ps *env.ProgramState, file, source string) (*env.ProgramState, error) {
+	switch val := loader.LoadStringNEW(" "+source+"\n", false, ps).(type) {
+	case env.Block:
+		ps = env.AddToProgramStateNEWWithLocation(ps, val, ps.Idx)
+		ps.Ctx = env.NewEnv(ps.Ctx)
+		evaldo.EvalBlockInj(ps, nil, true)
+		if ps.ErrorFlag || ps.FailureFlag {
+			return ps, errors.New(resultMessage(ps))
+		}
+		return ps, nil
+	case env.Error:
+		return nil, errors.New(val.Message)
+	}
+	return nil, errors.New("can't load " + file)
+}
+
+// resultMessage returns the message of the error in ps.Res.
+func resultMessage(ps *env.ProgramState) string {
+	switch e := ps.Res.(type) {
+	case *env.Error:
+		return e.Message
+	case env.Error:
+		return e.Message
+	case nil:
+		return "nil"
+	}
+	return ps.Res.Print(*ps.Idx)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/refaktor/rye/env"
+)
+
+// Tab in the REPL and widget/console completes the word before the cursor:
+// words of the context, words of a context path like widget/but, and after
+// a word holding a native, methods like b .set-. When more than one name
+// fits, Tab completes as far as they agree and then lists them, with the Go
+// declarations from godocs.gen.go.
+
+// completion is a name that completes a word, with its Go declaration if it
+// has one.
+type completion struct {
+	word, decl string
+}
+
+// completeCode completes the word at the end of head, the code before the
+// cursor. It returns head with the word completed as far as the names that
+// fit agree, and the names if that doesn't complete it further.
+func completeCode(ps *env.ProgramState, head string) (string, []completion) {
+	start := strings.LastIndexAny(head, " \t\n[]{}()\",:") + 1
+	items := completions(ps, head[:start], head[start:])
+	if len(items) == 0 {
+		return head, nil
+	}
+	prefix := items[0].word
+	for _, c := range items[1:] {
+		for !strings.HasPrefix(c.word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(items) == 1 || len(prefix) > len(head)-start {
+		return head[:start] + prefix, nil
+	}
+	return head, items
+}
+
+// completions returns the names that complete word, sorted. before is the
+// code before it.
+func completions(ps *env.ProgramState, before, word string) []completion {
+	var items []completion
+	switch {
+	case ps == nil:
+	case strings.HasPrefix(word, ".") || strings.HasPrefix(word, "|"):
+		fields := strings.Fields(before)
+		if len(fields) == 0 {
+			break
+		}
+		idx, ok := ps.Idx.GetIndex(fields[len(fields)-1])
+		if !ok {
+			break
+		}
+		obj, ok := ps.Ctx.Get(idx)
+		if !ok {
+			break
+		}
+		kind := ps.Idx.GetWord(obj.GetKind())
+		for _, idx := range ps.Gen.GetMethods(obj.GetKind()) {
+			name := ps.Idx.GetWord(idx)
+			if strings.HasPrefix(name, word[1:]) {
+				d, _ := kindDoc(kind, strings.TrimRight(name, "?!"))
+				items = append(items, completion{word[:1] + name, d.Decl})
+			}
+		}
+	case strings.Contains(word, "/"):
+		parts := strings.Split(word, "/")
+		ctx := ps.Ctx
+		for i, part := range parts[:len(parts)-1] {
+			idx, ok := ps.Idx.GetIndex(part)
+			if !ok {
+				return nil
+			}
+			var obj env.Object
+			if i == 0 {
+				obj, ok = ctx.Get(idx)
+			} else {
+				obj, ok = ctx.GetCurrent(idx)
+			}
+			sub, isCtx := obj.(env.RyeCtx)
+			if !ok || !isCtx {
+				return nil
+			}
+			ctx = &sub
+		}
+		pkg, ok := resolvePackage(strings.Join(parts[:len(parts)-1], "/"))
+		if !ok {
+			pkg, _ = resolvePackage(parts[len(parts)-2])
+		}
+		path := word[:strings.LastIndex(word, "/")+1]
+		for idx := range ctx.GetState() {
+			name := ps.Idx.GetWord(idx)
+			if strings.HasPrefix(name, parts[len(parts)-1]) {
+				items = append(items, completion{path + name, goDocs[pkg][name].Decl})
+			}
+		}
+	default:
+		seen := map[string]bool{}
+		for ctx := ps.Ctx; ctx != nil; ctx = ctx.Parent {
+			for idx := range ctx.GetState() {
+				name := ps.Idx.GetWord(idx)
+				if strings.HasPrefix(name, word) && !strings.Contains(name, "(") && !seen[name] {
+					seen[name] = true
+					items = append(items, completion{name, ""})
+				}
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].word < items[j].word })
+	return items
+}
+
+// formatCompletions returns the names one per line, with their declarations
+// on the same line.
+func formatCompletions(items []completion) string {
+	width := 0
+	for _, c := range items {
+		width = max(width, len(c.word))
+	}
+	var b strings.Builder
+	for _, c := range items {
+		b.WriteString(c.word)
+		if c.decl != "" {
+			decl := strings.Join(strings.Fields(c.decl), " ")
+			decl = strings.NewReplacer("( ", "(", ", )", ")").Replace(decl)
+			b.WriteString(strings.Repeat(" ", width-len(c.word)+2))
+			b.WriteString(decl)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompleteCode(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		greeting-long: "Hi"
+		greeting-short: "Hi"
+		b: widget/button "Hi" fn { } { }
+	`)
+	tests := []struct {
+		name, head, want string
+		items            []string // the first names listed, if any
+	}{
+		{"word", `print greeting-l`, `print greeting-long`, nil},
+		{"word as far as names agree", `print gree`, `print greeting-`, nil},
+		{"word listing names", `print greeting-`, `print greeting-`, []string{"greeting-long", "greeting-short"}},
+		{"unknown word", `print zzz`, `print zzz`, nil},
+		{"context path", `widget/button-with-i`, `widget/button-with-icon`, nil},
+		{"context path listing names", `{ widget/button`, `{ widget/button`, []string{"widget/button"}},
+		{"method", `b .set-te`, `b .set-text`, nil},
+		{"pipe method", `b |set-te`, `b |set-text`, nil},
+		{"method of unknown word", `zzz .set-te`, `zzz .set-te`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, items := completeCode(ps, tt.head)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if len(items) < len(tt.items) || (tt.items == nil) != (items == nil) {
+				t.Fatalf("got names %v, want %q", items, tt.items)
+			}
+			for i, name := range tt.items {
+				if items[i].word != name {
+					t.Errorf("got name %q, want %q", items[i].word, name)
+				}
+			}
+		})
+	}
+
+	// Names of Go functions are listed with their declarations.
+	_, items := completeCode(ps, "widget/button")
+	if len(items) == 0 || !strings.HasPrefix(items[0].decl, "func NewButton(") {
+		t.Errorf("got %v, want widget/button with its declaration", items)
+	}
+	_, items = completeCode(ps, "b .set-")
+	found := false
+	for _, c := range items {
+		if c.word == ".set-text" {
+			found = true
+			if !strings.Contains(c.decl, "SetText") {
+				t.Errorf("got declaration %q of .set-text", c.decl)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("got %v, want .set-text among them", items)
+	}
+}
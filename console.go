@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+	"github.com/refaktor/rye/loader"
+	"golang.org/x/term"
+)
+
+// With -repl, running the app with app .run or window .show-and-run also
+// starts a REPL in the terminal, so the app can be changed while it runs.
+// widget/console is the same in a widget. Code is evaluated on the UI thread
+// in the context the app was run or the console created in, so it sees the
+// words of the script and the words it sets stay for the next lines. Tab
+// completes words, see completeCode.
+
+// replMode is set by the -repl flag.
+var replMode bool
+
+// replStarted makes sure the terminal has only one REPL.
+var replStarted sync.Once
+
+func init() {
+	flag.BoolVar(&replMode, "repl", false, "Read Rye code from the terminal while the app runs and evaluate it on the UI thread")
+
+	builtins_fyne_widget["console"] = &env.VarBuiltin{
+		Argsn: 0,
+		Doc:   "Creates a console widget that evaluates Rye code in the current context.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			res, _ := conv_fyne_io_fyne_v2_CanvasObject_toRye(ps, nil, newConsole(ps))
+			return res
+		},
+	}
+}
+
+// addRepl makes app .run and window .show-and-run start the terminal REPL
+// with -repl. It wraps the generated builtins, so it is called once the
+// builtins are registered.
+func addRepl() {
+	if !replMode {
+		return
+	}
+	m := builtins_fyne
+	for _, name := range []string{"go(fyne/App)//run", "go(fyne/Window)//show-and-run"} {
+		run := m[name]
+		m[name] = &env.VarBuiltin{
+			Argsn: run.Argsn,
+			Doc:   run.Doc,
+			Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+				startRepl(func() *env.ProgramState { return ps })
+				return run.Fn(ps, args...)
+			},
+		}
+	}
+}
+
+// startRepl starts the terminal REPL, evaluating in the program state that
+// current returns on the UI thread, unless it was started already.
+func startRepl(current func() *env.ProgramState) {
+	replStarted.Do(func() {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			go runTermRepl(current)
+		} else {
+			go runRepl(os.Stdin, os.Stdout, current)
+		}
+	})
+}
+
+// runTermRepl is runRepl for a terminal, where lines are edited with
+// history and Tab completion. The terminal is in raw mode only while a
+// line is read, and Ctrl-C still interrupts the program.
+func runTermRepl(current func() *env.ProgramState) {
+	fd := int(os.Stdin.Fd())
+	var raw *term.State
+	in := interruptReader{os.Stdin, func() {
+		term.Restore(fd, raw)
+		p, err := os.FindProcess(os.Getpid())
+		if err != nil || p.Signal(os.Interrupt) != nil {
+			os.Exit(130)
+		}
+	}}
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, os.Stdout}, "rye> ")
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		var head string
+		var items []completion
+		fyne.DoAndWait(func() {
+			head, items = completeCode(current(), line[:pos])
+		})
+		if items != nil {
+			t.Write([]byte(formatCompletions(items)))
+		}
+		return head + line[pos:], len(head), true
+	}
+	var code strings.Builder
+	for {
+		var err error
+		if raw, err = term.MakeRaw(fd); err != nil {
+			runRepl(os.Stdin, os.Stdout, current)
+			return
+		}
+		line, err := t.ReadLine()
+		term.Restore(fd, raw)
+		if err != nil {
+			return
+		}
+		code.WriteString(line)
+		code.WriteByte('\n')
+		if openBrackets(code.String()) > 0 {
+			t.SetPrompt("   > ")
+			continue
+		}
+		var res string
+		fyne.DoAndWait(func() {
+			res = evalCode(current(), code.String(), nil)
+		})
+		code.Reset()
+		if res != "" {
+			fmt.Println(res)
+		}
+		t.SetPrompt("rye> ")
+	}
+}
+
+// interruptReader calls interrupt when Ctrl-C is read, which raw mode
+// doesn't turn into a signal.
+type interruptReader struct {
+	r         io.Reader
+	interrupt func()
+}
+
+func (r interruptReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if bytes.IndexByte(p[:n], 3) >= 0 {
+		r.interrupt()
+	}
+	return n, err
+}
+
+// runRepl reads code from r until it ends and evaluates it on the UI
+// thread. Lines with open brackets continue on the next line.
+func runRepl(r io.Reader, w io.Writer, current func() *env.ProgramState) {
+	sc := bufio.NewScanner(r)
+	var code strings.Builder
+	fmt.Fprint(w, "rye> ")
+	for sc.Scan() {
+		code.WriteString(sc.Text())
+		code.WriteByte('\n')
+		if openBrackets(code.String()) > 0 {
+			fmt.Fprint(w, "   > ")
+			continue
+		}
+		var res string
+		fyne.DoAndWait(func() {
+			res = evalCode(current(), code.String(), nil)
+		})
+		code.Reset()
+		if res != "" {
+			fmt.Fprintln(w, res)
+		}
+		fmt.Fprint(w, "rye> ")
+	}
+}
+
+// newConsole returns a console evaluating code in ps.
+func newConsole(ps *env.ProgramState) fyne.CanvasObject {
+	output := widget.NewLabel("")
+	output.TextStyle = fyne.TextStyle{Monospace: true}
+	output.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(output)
+	input := newConsoleEntry()
+	input.SetPlaceHolder("Rye code")
+	input.onTab = func() {
+		text := []rune(input.Text)
+		col := min(input.CursorColumn, len(text))
+		head, items := completeCode(ps, string(text[:col]))
+		if items != nil {
+			output.SetText(output.Text + formatCompletions(items))
+			scroll.ScrollToBottom()
+		}
+		input.SetText(head + string(text[col:]))
+		input.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEnd})
+		for range text[col:] {
+			input.TypedKey(&fyne.KeyEvent{Name: fyne.KeyLeft})
+		}
+	}
+	var code strings.Builder
+	input.OnSubmitted = func(line string) {
+		prompt := "rye> "
+		if code.Len() > 0 {
+			prompt = "   > "
+		}
+		output.SetText(output.Text + prompt + line + "\n")
+		input.SetText("")
+		code.WriteString(line)
+		code.WriteByte('\n')
+		if openBrackets(code.String()) > 0 {
+			scroll.ScrollToBottom()
+			return
+		}
+		if res := evalCode(ps, code.String(), nil); res != "" {
+			output.SetText(output.Text + res + "\n")
+		}
+		code.Reset()
+		scroll.ScrollToBottom()
+	}
+	return container.NewBorder(nil, input, nil, nil, scroll)
+}
+
+// consoleEntry is the input of the console, where Tab completes the code
+// instead of moving the focus.
+type consoleEntry struct {
+	widget.Entry
+	onTab func()
+}
+
+func newConsoleEntry() *consoleEntry {
+	e := &consoleEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *consoleEntry) AcceptsTab() bool {
+	return true
+}
+
+func (e *consoleEntry) TypedKey(key *fyne.KeyEvent) {
+	if key.Name == fyne.KeyTab && e.onTab != nil {
+		e.onTab()
+		return
+	}
+	e.Entry.TypedKey(key)
+}
+
+// evalCode evaluates code in a fork of base, with inj injected if it isn't
+// nil, and returns the result as the Rye console shows it.
+func evalCode(base *env.ProgramState, code string, inj env.Object) (res string) {
+	if base == nil {
+		return "Error: there is no script to evaluate in"
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			res = fmt.Sprintf("Error: panic: %v", r)
+		}
+	}()
+	ps := forkProgramState(base)
+	switch val := loader.LoadStringNEW(" "+code+"\n", false, ps).(type) {
+	case env.Block:
+		ps.Ser = val.Series
+		evaldo.EvalBlockInj(ps, inj, inj != nil)
+		if ps.ErrorFlag || ps.FailureFlag {
+			return "Error: " + resultMessage(ps)
+		}
+		if ps.Res == nil {
+			return ""
+		}
+		return ps.Res.Inspect(*ps.Idx)
+	case env.Error:
+		return "Error: " + ansiEscapes.ReplaceAllString(val.Message, "")
+	}
+	return ""
+}
+
+// openBrackets returns how many brackets of code are not closed yet,
+// ignoring strings and comments. Strings are quoted with " or `, and, as in
+// Rye's loader, a backslash before the quote doesn't end them.
+func openBrackets(code string) int {
+	open := 0
+	var quote rune // of the string code is in, 0 if it isn't
+	inComment, escaped := false, false
+	for _, r := range code {
+		switch {
+		case inComment:
+			inComment = r != '\n'
+		case quote != 0:
+			if r == quote && !escaped {
+				quote = 0
+			}
+			escaped = r == '\\'
+		case r == '"' || r == '`':
+			quote = r
+		case r == ';':
+			inComment = true
+		case r == '[' || r == '{' || r == '(':
+			open++
+		case r == ']' || r == '}' || r == ')':
+			open--
+		}
+	}
+	return open
+}
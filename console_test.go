@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestEvalCode(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `import\go\all`)
+	label := evalTest(t, ps, `widget/label "Hi"`).Res
+	tests := []struct {
+		name, code, want string
+	}{
+		{"expression", `1 + 2`, "[Integer: 3]"},
+		{"words stay set", `x: 41`, "[Integer: 41]"},
+		{"set word", `x + 1`, "[Integer: 42]"},
+		{"nothing", ``, ""},
+		{"failure", `fail "broken"`, "Error: broken"},
+		{"unknown word", `nothing-here`, "Error: Word not found: `nothing-here`"},
+		{"bad syntax", `{ 1`, "Error: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evalCode(ps, tt.code, nil); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := evalCode(ps, `.text?`, label); got != `[String: Hi]` {
+		t.Errorf("got %q with the label injected, want its text", got)
+	}
+	if got := evalCode(nil, `1`, nil); got != "Error: there is no script to evaluate in" {
+		t.Errorf("got %q without a script", got)
+	}
+	evalCode(ps, `.set-text "Bye"`, label)
+	if l := label.(env.Native).Value.(*widget.Label); l.Text != "Bye" {
+		t.Errorf("got text %q after setting it on the injected label, want \"Bye\"", l.Text)
+	}
+}
+
+func TestOpenBrackets(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{`print 1`, 0},
+		{`either x {`, 1},
+		{`either x { print [ 1`, 2},
+		{`fn { x } { x }`, 0},
+		{`{ ( [ ] ) }`, 0},
+		{`}`, -1},
+		{`print "{"`, 0},
+		{`print "a \" {" {`, 1},
+		{"print `{\n[`", 0},
+		{"print `\"{` {", 1},
+		{`print "{`, 0},
+		{`{ ; } comment`, 1},
+		{"{ ; }\n}", 0},
+		{`"; {" {`, 1},
+	}
+	for _, tt := range tests {
+		if got := openBrackets(tt.code); got != tt.want {
+			t.Errorf("got %d open brackets in %q, want %d", got, tt.code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// customLayout is a fyne.Layout implemented by two Rye functions. The
+// objects are converted to a block once and the block is reused for as long
+// as the container holds the same objects, so resizing doesn't convert them
+// again.
+type customLayout struct {
+	ps      *env.ProgramState
+	layout  env.Function
+	minSize env.Function
+
+	objects []fyne.CanvasObject
+	block   env.Block
+}
+
+func (l *customLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	obj, _ := conv_fyne_io_fyne_v2_Size_toRye(l.ps, nil, size)
+	l.call(l.layout, l.objectsToRye(objects), obj)
+}
+
+func (l *customLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	ps, ok := l.call(l.minSize, l.objectsToRye(objects))
+	if !ok {
+		return fyne.Size{}
+	}
+	size, err := conv_fyne_io_fyne_v2_Size_fromRye(ps, nil, ps.Res)
+	if err != nil {
+		showFunctionError(ps, l.minSize, err)
+	}
+	return size
+}
+
+func (l *customLayout) call(fn env.Function, args ...env.Object) (*env.ProgramState, bool) {
+	ps := forkProgramState(l.ps)
+	evaldo.CallFunctionArgsN(fn, ps, nil, args...)
+	if e, ok := ps.Res.(*env.Error); ok {
+		showFunctionError(ps, fn, errors.New(e.Message))
+		return ps, false
+	}
+	return ps, true
+}
+
+// objectsToRye returns the objects as a block, reusing the last one if the
+// objects didn't change.
+func (l *customLayout) objectsToRye(objects []fyne.CanvasObject) env.Block {
+	if sameObjects(l.objects, objects) {
+		return l.block
+	}
+	blk, _ := conv_slice_fyne_io_fyne_v2_CanvasObject_toRye(l.ps, nil, objects)
+	l.objects = append(l.objects[:0], objects...)
+	l.block = blk
+	return blk
+}
+
+func sameObjects(a, b []fyne.CanvasObject) bool {
+	if a == nil || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	builtins_fyne_layout["custom"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Creates a layout from a function of the objects and the size that positions the objects, and a function of the objects that returns their minimum size.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			layout, ok := args[0].(env.Function)
+			if !ok || layout.Argsn != 2 {
+				ps.FailureFlag = true
+				return env.NewError("expected layout function with 2 args, but got " + objectType(ps, args[0]))
+			}
+			minSize, ok := args[1].(env.Function)
+			if !ok || minSize.Argsn != 1 {
+				ps.FailureFlag = true
+				return env.NewError("expected min-size function with 1 arg, but got " + objectType(ps, args[1]))
+			}
+			l := &customLayout{ps: forkProgramState(ps), layout: layout, minSize: minSize}
+			return *env.NewNative(ps.Idx, fyne.Layout(l), "go(fyne/Layout)")
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestCustomLayout(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		column: layout/custom
+			fn { objects size } {
+				var 'y 0.0
+				for objects { ::o
+					o .resize fyne/size size .width? 10
+					o .move fyne/pos 5 y
+					y:: y + 15.0
+				}
+			}
+			fn { objects } {
+				n: length? objects
+				fyne/size 40 n * 15
+			}
+		top: widget/label "top"
+		bottom: widget/label "bottom"
+		c: container/new column [ top bottom ]
+	`)
+	c := testValue[*fyne.Container](t, ps, "c")
+	w := test.NewWindow(c)
+	defer w.Close()
+	w.Resize(fyne.NewSize(200, 100))
+
+	if got, want := c.MinSize(), fyne.NewSize(40, 30); got != want {
+		t.Errorf("got min size %v, want %v", got, want)
+	}
+	for i, name := range []string{"top", "bottom"} {
+		l := testValue[*widget.Label](t, ps, name)
+		if got, want := l.Position(), fyne.NewPos(5, float32(i*15)); got != want {
+			t.Errorf("got %s at %v, want %v", name, got, want)
+		}
+		if got, want := l.Size(), fyne.NewSize(c.Size().Width, 10); got != want {
+			t.Errorf("got %s size %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// customWidget is a Fyne widget implemented by the functions of a Rye
+// context:
+//
+//	create-renderer        returns the widget's canvas objects, one or a block
+//	layout objects size    positions the objects
+//	min-size objects       returns the minimum size
+//	refresh objects        optional, updates the objects, also on theme changes
+//	tapped event           optional, also tapped-secondary
+//	mouse-in event         optional, also mouse-moved and mouse-out
+//	dragged event          optional, also drag-end
+//	typed-key key          optional, the widget can then be focused and also
+//	                       gets typed-rune, focus-gained and focus-lost
+//
+// The widget is refreshed after each of these handlers, except mouse-moved.
+//
+// Since Fyne finds out what a widget can do from its methods, draggable and
+// focusable widgets have their own types embedding customWidget.
+type customWidget struct {
+	widget.BaseWidget
+	ps    *env.ProgramState
+	ctx   *env.RyeCtx
+	outer fyne.Widget
+}
+
+type draggableCustomWidget struct{ customWidget }
+
+type focusableCustomWidget struct{ customWidget }
+
+type draggableFocusableCustomWidget struct{ customWidget }
+
+// customWidgetRequired are the functions a context must define.
+var customWidgetRequired = []string{"create-renderer", "layout", "min-size"}
+
+func newCustomWidget(ps *env.ProgramState, ctx *env.RyeCtx) (fyne.Widget, error) {
+	for _, name := range customWidgetRequired {
+		if _, ok := customWidgetFunction(ps, ctx, name); !ok {
+			return nil, errors.New("expected context with function " + name)
+		}
+	}
+	_, draggable := customWidgetFunction(ps, ctx, "dragged")
+	_, focusable := customWidgetFunction(ps, ctx, "typed-key")
+	var w *customWidget
+	switch {
+	case draggable && focusable:
+		x := &draggableFocusableCustomWidget{}
+		x.outer, w = x, &x.customWidget
+	case draggable:
+		x := &draggableCustomWidget{}
+		x.outer, w = x, &x.customWidget
+	case focusable:
+		x := &focusableCustomWidget{}
+		x.outer, w = x, &x.customWidget
+	default:
+		w = &customWidget{}
+		w.outer = w
+	}
+	w.ps, w.ctx = forkProgramState(ps), ctx
+	w.ExtendBaseWidget(w.outer)
+	return w.outer, nil
+}
+
+// customWidgetFunction returns the function name of ctx. Only ctx itself is
+// looked in, as its parent is usually the script, which can have a word like
+// layout for something else.
+func customWidgetFunction(ps *env.ProgramState, ctx *env.RyeCtx, name string) (env.Function, bool) {
+	idx, ok := ps.Idx.GetIndex(name)
+	if !ok {
+		return env.Function{}, false
+	}
+	obj, ok := ctx.GetCurrent(idx)
+	if !ok {
+		return env.Function{}, false
+	}
+	fn, ok := obj.(env.Function)
+	return fn, ok
+}
+
+// call calls the function name of the context, if it is defined, with as
+// many of args as it accepts. ok is false if the function isn't defined or
+// failed.
+func (w *customWidget) call(name string, args ...env.Object) (_ env.Object, ok bool) {
+	fn, ok := customWidgetFunction(w.ps, w.ctx, name)
+	if !ok {
+		return nil, false
+	}
+	ps := forkProgramState(w.ps)
+	if fn.Argsn > len(args) {
+		showFunctionError(ps, fn, errors.New("expected "+name+" to accept at most "+strconv.Itoa(len(args))+" args"))
+		return nil, false
+	}
+	evaldo.CallFunctionArgsN(fn, ps, w.ctx, args[:fn.Argsn]...)
+	if e, ok := ps.Res.(*env.Error); ok {
+		showFunctionError(ps, fn, errors.New(e.Message))
+		return nil, false
+	}
+	return ps.Res, true
+}
+
+func (w *customWidget) CreateRenderer() fyne.WidgetRenderer {
+	r := &customRenderer{w: w, ryeObjects: *env.NewBlock(*env.NewTSeries(nil))}
+	res, ok := w.call("create-renderer")
+	if !ok {
+		return r
+	}
+	blk, ok := res.(env.Block)
+	if !ok {
+		blk = *env.NewBlock(*env.NewTSeries([]env.Object{res}))
+	}
+	objects, err := conv_slice_fyne_io_fyne_v2_CanvasObject_fromRye(w.ps, w.ctx, blk)
+	if err != nil {
+		fn, _ := customWidgetFunction(w.ps, w.ctx, "create-renderer")
+		showFunctionError(forkProgramState(w.ps), fn, err)
+		return r
+	}
+	r.objects, r.ryeObjects = objects, blk
+	return r
+}
+
+func (w *customWidget) Tapped(e *fyne.PointEvent) {
+	if f, ok := w.outer.(fyne.Focusable); ok {
+		if c := fyne.CurrentApp().Driver().CanvasForObject(w.outer); c != nil {
+			c.Focus(f)
+		}
+	}
+	w.handleEvent("tapped", e)
+}
+
+func (w *customWidget) TappedSecondary(e *fyne.PointEvent) {
+	w.handleEvent("tapped-secondary", e)
+}
+
+func (w *customWidget) MouseIn(e *desktop.MouseEvent) {
+	obj, _ := conv_ptr_fyne_io_fyne_v2_driver_desktop_MouseEvent_toRye(w.ps, w.ctx, e)
+	w.handle("mouse-in", obj)
+}
+
+// MouseMoved doesn't refresh the widget, as it is called for every move.
+func (w *customWidget) MouseMoved(e *desktop.MouseEvent) {
+	obj, _ := conv_ptr_fyne_io_fyne_v2_driver_desktop_MouseEvent_toRye(w.ps, w.ctx, e)
+	w.call("mouse-moved", obj)
+}
+
+func (w *customWidget) MouseOut() {
+	w.handle("mouse-out")
+}
+
+// handle calls the handler name and refreshes the widget if it succeeded,
+// so handlers only need to change the state the refresh function shows.
+func (w *customWidget) handle(name string, args ...env.Object) {
+	if _, ok := w.call(name, args...); ok {
+		w.outer.Refresh()
+	}
+}
+
+func (w *customWidget) handleEvent(name string, e *fyne.PointEvent) {
+	obj, _ := conv_ptr_fyne_io_fyne_v2_PointEvent_toRye(w.ps, w.ctx, e)
+	w.handle(name, obj)
+}
+
+func (w *customWidget) dragged(e *fyne.DragEvent) {
+	obj, _ := conv_ptr_fyne_io_fyne_v2_DragEvent_toRye(w.ps, w.ctx, e)
+	w.handle("dragged", obj)
+}
+
+func (w *customWidget) dragEnd() { w.handle("drag-end") }
+
+func (w *customWidget) focusGained() { w.handle("focus-gained") }
+
+func (w *customWidget) focusLost() { w.handle("focus-lost") }
+
+func (w *customWidget) typedRune(r rune) { w.handle("typed-rune", *env.NewString(string(r))) }
+
+func (w *customWidget) typedKey(e *fyne.KeyEvent) {
+	w.handle("typed-key", *env.NewString(string(e.Name)))
+}
+
+func (w *draggableCustomWidget) Dragged(e *fyne.DragEvent) { w.dragged(e) }
+func (w *draggableCustomWidget) DragEnd()                  { w.dragEnd() }
+
+func (w *focusableCustomWidget) FocusGained()              { w.focusGained() }
+func (w *focusableCustomWidget) FocusLost()                { w.focusLost() }
+func (w *focusableCustomWidget) TypedRune(r rune)          { w.typedRune(r) }
+func (w *focusableCustomWidget) TypedKey(e *fyne.KeyEvent) { w.typedKey(e) }
+
+func (w *draggableFocusableCustomWidget) Dragged(e *fyne.DragEvent) { w.dragged(e) }
+func (w *draggableFocusableCustomWidget) DragEnd()                  { w.dragEnd() }
+func (w *draggableFocusableCustomWidget) FocusGained()              { w.focusGained() }
+func (w *draggableFocusableCustomWidget) FocusLost()                { w.focusLost() }
+func (w *draggableFocusableCustomWidget) TypedRune(r rune)          { w.typedRune(r) }
+func (w *draggableFocusableCustomWidget) TypedKey(e *fyne.KeyEvent) { w.typedKey(e) }
+
+// customRenderer renders a customWidget with the functions of its context.
+type customRenderer struct {
+	w          *customWidget
+	objects    []fyne.CanvasObject
+	ryeObjects env.Block
+}
+
+func (r *customRenderer) Layout(size fyne.Size) {
+	obj, _ := conv_fyne_io_fyne_v2_Size_toRye(r.w.ps, r.w.ctx, size)
+	r.w.call("layout", r.ryeObjects, obj)
+}
+
+func (r *customRenderer) MinSize() fyne.Size {
+	res, ok := r.w.call("min-size", r.ryeObjects)
+	if !ok {
+		return fyne.Size{}
+	}
+	size, err := conv_fyne_io_fyne_v2_Size_fromRye(r.w.ps, r.w.ctx, res)
+	if err != nil {
+		fn, _ := customWidgetFunction(r.w.ps, r.w.ctx, "min-size")
+		showFunctionError(forkProgramState(r.w.ps), fn, err)
+	}
+	return size
+}
+
+func (r *customRenderer) Refresh() {
+	r.w.call("refresh", r.ryeObjects)
+	for _, o := range r.objects {
+		o.Refresh()
+	}
+}
+
+func (r *customRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *customRenderer) Destroy() {}
+
+func init() {
+	builtins_fyne_widget["custom"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a widget implemented by the functions of a context: create-renderer, layout and min-size, and optionally refresh, tapped, mouse-in, dragged, typed-key and others.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			var ctx *env.RyeCtx
+			switch x := args[0].(type) {
+			case env.RyeCtx:
+				ctx = &x
+			case *env.RyeCtx:
+				ctx = x
+			default:
+				ps.FailureFlag = true
+				return env.NewError("expected context, but got " + objectType(ps, args[0]))
+			}
+			w, err := newCustomWidget(ps, ctx)
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			return *env.NewNative(ps.Idx, w, "go(fyne/Widget)")
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+// customWidgetBase are the functions of both test widgets. Custom widgets
+// only look in their context itself, so they can't be inherited.
+const customWidgetBase = `
+	create-renderer: fn { } { widget/label "custom" }
+	layout: fn { objects size } { objects .first .resize size }
+	min-size: fn { objects } { fyne/size 50 20 }
+	refresh: fn { objects } { events .Send "refresh" }
+`
+
+// customWidgetScript defines a draggable and a focusable custom widget,
+// whose handlers send their names and arguments to events.
+const customWidgetScript = `
+	import\go\all
+	events: channel 20
+	draggable: widget/custom context {` + customWidgetBase + `
+		dragged: fn { e } { events .Send "dragged" }
+		drag-end: fn { } { events .Send "drag-end" }
+	}
+	focusable: widget/custom context {` + customWidgetBase + `
+		typed-key: fn { key } { events .Send key }
+		typed-rune: fn { r } { events .Send r }
+		focus-gained: fn { } { events .Send "focus-gained" }
+		focus-lost: fn { } { events .Send "focus-lost" }
+	}
+`
+
+// customWidgetEvents returns the events sent by the handlers so far.
+func customWidgetEvents(t *testing.T, ps *env.ProgramState) []string {
+	t.Helper()
+	events := testValue[chan *env.Object](t, ps, "events")
+	var got []string
+	for len(events) > 0 {
+		if s, ok := (*<-events).(env.String); ok && s.Value != "refresh" {
+			got = append(got, s.Value)
+		}
+	}
+	return got
+}
+
+func TestCustomWidgetDraggable(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), customWidgetScript)
+	w := testValue[fyne.Widget](t, ps, "draggable")
+	if _, ok := w.(fyne.Focusable); ok {
+		t.Error("a widget without typed-key is focusable")
+	}
+	win := test.NewWindow(w)
+	defer win.Close()
+	win.Resize(fyne.NewSize(100, 100))
+	if got, want := w.MinSize(), fyne.NewSize(50, 20); got != want {
+		t.Errorf("got min size %v, want %v", got, want)
+	}
+	label := test.WidgetRenderer(w).Objects()[0].(*widget.Label)
+	if label.Text != "custom" || label.Size() != w.Size() {
+		t.Errorf("got %q sized %v, want \"custom\" laid out to %v", label.Text, label.Size(), w.Size())
+	}
+
+	customWidgetEvents(t, ps)
+	test.Drag(win.Canvas(), fyne.NewPos(5, 5), 10, 0)
+	got := customWidgetEvents(t, ps)
+	if len(got) != 2 || got[0] != "dragged" || got[1] != "drag-end" {
+		t.Errorf("got events %q, want dragged and drag-end", got)
+	}
+}
+
+func TestCustomWidgetFocusable(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), customWidgetScript)
+	w := testValue[fyne.Widget](t, ps, "focusable")
+	f, ok := w.(fyne.Focusable)
+	if !ok {
+		t.Fatal("a widget with typed-key isn't focusable")
+	}
+	if _, ok := w.(fyne.Draggable); ok {
+		t.Error("a widget without dragged is draggable")
+	}
+	win := test.NewWindow(w)
+	defer win.Close()
+
+	customWidgetEvents(t, ps)
+	test.Tap(w.(fyne.Tappable))
+	f.TypedRune('a')
+	f.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	win.Canvas().Unfocus()
+	want := []string{"focus-gained", "a", "Return", "focus-lost"}
+	got := customWidgetEvents(t, ps)
+	if len(got) != len(want) {
+		t.Fatalf("got events %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got events %q, want %q", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// A binary made by `rye-fyne build` embeds the directory of an app and runs
+// its main.rye instead of the Rye runner. Import, Read, Read\bytes and
+// Read\lines read relative paths from the embedded directory first. Files
+// that have to be on disk, like SQLite databases, are copied there with
+// embedded\path.
+
+// embeddedApp is the embedded app directory, nil in rye-fyne itself, and
+// embeddedMetadata its metadata. They are set with embedApp by the file
+// `rye-fyne build` generates.
+var (
+	embeddedApp      fs.FS
+	embeddedMetadata fyne.AppMetadata
+)
+
+// embeddedMain is the file an embedded app starts with.
+const embeddedMain = "main.rye"
+
+// embeddedPrefix is the directory Rye's import expects embedded files in.
+const embeddedPrefix = "buildtemp/"
+
+// embeddedFiles gives Rye's import the files of the embedded app.
+type embeddedFiles struct {
+	fs.FS
+}
+
+func (f embeddedFiles) ReadFile(name string) ([]byte, error) {
+	return readAppFile(strings.TrimPrefix(name, embeddedPrefix))
+}
+
+func init() {
+	baseBuiltins["embedded\\path"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the path of a copy of an embedded file on disk, for files that are opened by path, like databases. The copy is made once and kept, so changes to it stay. Other paths are returned as they are.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			u, ok := args[0].(env.Uri)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected uri, but got " + objectType(ps, args[0]))
+			}
+			p, err := extractAppFile(u.GetPath())
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			return *env.NewString(p)
+		},
+	}
+}
+
+// embeddedBuiltins replace the Rye builtins that read files in an embedded
+// app.
+var embeddedBuiltins = map[string]*env.VarBuiltin{
+	"file-uri//Read": {
+		Argsn: 1,
+		Doc:   "Reads the entire content of an embedded or other file as a string.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			data, err := readAppUri(ps, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			return *env.NewString(string(data))
+		},
+	},
+	"file-uri//Read\\bytes": {
+		Argsn: 1,
+		Doc:   "Reads the entire content of an embedded or other file as bytes.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			data, err := readAppUri(ps, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			return *env.NewNative(ps.Idx, data, "bytes")
+		},
+	},
+	"file-uri//Read\\lines": {
+		Argsn: 1,
+		Doc:   "Reads an embedded or other file and returns its content as a block of lines.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			data, err := readAppUri(ps, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			var lines []env.Object
+			sc := bufio.NewScanner(bytes.NewReader(data))
+			for sc.Scan() {
+				lines = append(lines, *env.NewString(sc.Text()))
+			}
+			return *env.NewBlock(*env.NewTSeries(lines))
+		},
+	},
+}
+
+// embedApp makes files the embedded app, with the metadata for Fyne apps.
+func embedApp(files fs.FS, metadata fyne.AppMetadata) {
+	embeddedApp = files
+	embeddedMetadata = metadata
+	app.SetMetadata(metadata)
+}
+
+// runEmbedded runs the main.rye of the embedded app.
+func runEmbedded(regfn func(*env.ProgramState) error) int {
+	source, err := fs.ReadFile(embeddedApp, embeddedMain)
+	if err == nil {
+		var ps *env.ProgramState
+		ps, err = newProgramState(regfn, embeddedMain, nil)
+		if err == nil {
+			ps.Embedded = true
+			var files any = embeddedFiles{embeddedApp}
+			ps.EmbeddedFS = &files
+			evaldo.RegisterVarBuiltins2(embeddedBuiltins, ps, "embedded")
+			_, err = evalSource(ps, embeddedMain, string(source))
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func readAppUri(ps *env.ProgramState, obj env.Object) ([]byte, error) {
+	u, ok := obj.(env.Uri)
+	if !ok {
+		return nil, errors.New("expected uri, but got " + objectType(ps, obj))
+	}
+	return readAppFile(u.GetPath())
+}
+
+// readAppFile reads a relative path from the embedded app, or else from
+// disk.
+func readAppFile(name string) ([]byte, error) {
+	if p, ok := embeddedPath(name); ok {
+		if data, err := fs.ReadFile(embeddedApp, p); err == nil {
+			return data, nil
+		}
+	}
+	return os.ReadFile(name)
+}
+
+// embeddedPath returns the path of a file in the embedded app, if name is
+// relative and in it.
+func embeddedPath(name string) (string, bool) {
+	if embeddedApp == nil || filepath.IsAbs(name) {
+		return "", false
+	}
+	p := path.Clean(filepath.ToSlash(name))
+	return p, fs.ValidPath(p)
+}
+
+// extractAppFile copies an embedded file to the app's directory in the user's
+// config directory, unless it is there already, and returns its path there.
+// Other files are left where they are.
+func extractAppFile(name string) (string, error) {
+	p, ok := embeddedPath(name)
+	if !ok {
+		return name, nil
+	}
+	data, err := fs.ReadFile(embeddedApp, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return name, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	id := embeddedMetadata.ID
+	if id == "" {
+		id = embeddedMetadata.Name
+	}
+	target := filepath.Join(dir, id, filepath.FromSlash(p))
+	if _, err := os.Stat(target); err == nil {
+		return target, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+	return target, os.WriteFile(target, data, 0o644)
+}
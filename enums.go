@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/refaktor/rye/env"
+)
+
+// Enum types of the bound packages are passed as words, so scripts can
+// write `img .fill-mode! 'original` and get 'original back from
+// `img .fill-mode?`. Integers are still accepted, and values without a name,
+// like combined key modifiers, are returned as before.
+//
+// The enum types are found by postgen.go, the named integer types of the
+// bound packages with constants, and added in enums.gen.go. Interfaces an
+// enum type implements, like desktop.Cursor for desktop.StandardCursor,
+// accept its words too.
+
+// enum maps the constants of a Go enum type to Rye words.
+type enum struct {
+	typeName string
+	values   map[string]int64
+	names    map[int64]string
+	order    []int64
+}
+
+var enums = map[reflect.Type]*enum{}
+
+func addEnum[T integer](typeName string, names map[T]string) {
+	e := &enum{
+		typeName: typeName,
+		values:   make(map[string]int64, len(names)),
+		names:    make(map[int64]string, len(names)),
+	}
+	for v, name := range names {
+		e.values[name] = int64(v)
+		e.names[int64(v)] = name
+		e.order = append(e.order, int64(v))
+	}
+	slices.Sort(e.order)
+	enums[reflect.TypeFor[T]()] = e
+}
+
+// enumFromRye converts a word or tagword naming a constant of the enum type
+// T. ok is false if obj is neither.
+func enumFromRye[T integer](ps *env.ProgramState, obj env.Object) (_ T, ok bool, err error) {
+	var idx int
+	switch x := obj.(type) {
+	case env.Word:
+		idx = x.Index
+	case env.Tagword:
+		idx = x.Index
+	default:
+		return 0, false, nil
+	}
+	e := enums[reflect.TypeFor[T]()]
+	name := ps.Idx.GetWord(idx)
+	v, found := e.values[name]
+	if !found {
+		var valid []string
+		for _, v := range e.order {
+			valid = append(valid, "'"+e.names[v])
+		}
+		return 0, true, errors.New("unknown " + e.typeName + " '" + name + ", expected one of " + strings.Join(valid, " "))
+	}
+	return T(v), true, nil
+}
+
+// enumToRye returns the word naming v. ok is false if v has no name.
+func enumToRye[T integer](ps *env.ProgramState, v T) (_ env.Object, ok bool) {
+	name, ok := enums[reflect.TypeFor[T]()].names[int64(v)]
+	if !ok {
+		return nil, false
+	}
+	return *env.NewWord(ps.Idx.IndexWord(name)), true
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestEnumWords(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		l: widget/label "l"
+		l .alignment! 'center
+		l .wrapping! 'word
+		align: l .alignment?
+		err: try { l .alignment! 'middle }
+	`)
+	l := testValue[*widget.Label](t, ps, "l")
+	if l.Alignment != fyne.TextAlignCenter || l.Wrapping != fyne.TextWrapWord {
+		t.Errorf("got alignment %v and wrapping %v", l.Alignment, l.Wrapping)
+	}
+	align, _ := ps.Ctx.Get(ps.Idx.IndexWord("align"))
+	if w, ok := align.(env.Word); !ok || ps.Idx.GetWord(w.Index) != "center" {
+		t.Errorf("got alignment %s, want 'center", objectType(ps, align))
+	}
+	obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("err"))
+	e, ok := obj.(*env.Error)
+	if !ok {
+		t.Fatalf("got %s, want error", objectType(ps, obj))
+	}
+	for _, s := range []string{"'middle", "'leading 'center 'trailing"} {
+		if !strings.Contains(e.Message, s) {
+			t.Errorf("error %q doesn't contain %q", e.Message, s)
+		}
+	}
+}
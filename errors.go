@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/refaktor/rye/env"
+)
+
+// goErrorFailures switches how Go error values that are not function results
+// reach Rye code, e.g. the err argument of a dialog/show-file-open callback.
+// When off they arrive as go(error) natives, when on as Rye failures.
+var goErrorFailures atomic.Bool
+
+func init() {
+	flag.BoolFunc("go-error-failures", "Pass Go errors to Rye code as failures instead of go(error) natives", func(s string) error {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		goErrorFailures.Store(v)
+		return nil
+	})
+
+	baseBuiltins["go-error-failures"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Turns passing of Go errors to Rye code as failures on or off.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			on, ok := args[0].(env.Boolean)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected boolean, but got " + objectType(ps, args[0]))
+			}
+			goErrorFailures.Store(on.Value)
+			return on
+		},
+	}
+}
+
+// goErrorToRye converts a Go error to a Rye error. The wrapped cause chain
+// becomes the chain of parents, and the fields of well known error types are
+// exposed as values. The original error is kept under "native".
+func goErrorToRye(ps *env.ProgramState, err error) *env.Error {
+	var parent *env.Error
+	if cause := unwrapError(err); cause != nil {
+		parent = goErrorToRye(ps, cause)
+	}
+	typ := fmt.Sprintf("%T", err)
+	if ae, ok := err.(*argError); ok {
+		// argError only adds the argument to the message.
+		typ = fmt.Sprintf("%T", ae.err)
+	}
+	values := map[string]env.Object{
+		"go-type": *env.NewString(typ),
+		"native":  *env.NewNative(ps.Idx, err, "go(error)"),
+	}
+	switch e := err.(type) {
+	case *fs.PathError:
+		values["op"] = *env.NewString(e.Op)
+		values["path"] = *env.NewString(e.Path)
+	case *os.LinkError:
+		values["op"] = *env.NewString(e.Op)
+		values["old"] = *env.NewString(e.Old)
+		values["new"] = *env.NewString(e.New)
+	case *os.SyscallError:
+		values["syscall"] = *env.NewString(e.Syscall)
+	}
+	return env.NewError4(0, err.Error(), parent, values)
+}
+
+// unwrapError returns the cause of err. Of errors joined with errors.Join only
+// the first one is followed.
+func unwrapError(err error) error {
+	if cause := errors.Unwrap(err); cause != nil {
+		return cause
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		if causes := joined.Unwrap(); len(causes) > 0 {
+			return causes[0]
+		}
+	}
+	return nil
+}
+
+// argError is an error converting an argument of a Go builtin, like
+// "arg 2 of container/border: expected ...". pos counts from 1, with the
+// receiver of a method as the first argument, and the name of the builtin is
+// set by namedBuiltin.
+type argError struct {
+	pos  int
+	name string
+	err  error
+}
+
+func (e *argError) Error() string {
+	if e.name == "" {
+		return "arg " + strconv.Itoa(e.pos) + ": " + e.err.Error()
+	}
+	return "arg " + strconv.Itoa(e.pos) + " of " + e.name + ": " + e.err.Error()
+}
+
+func (e *argError) Unwrap() error { return e.err }
+
+// namedBuiltin returns b with name in the errors about its arguments.
+func namedBuiltin(name string, b *env.VarBuiltin) *env.VarBuiltin {
+	fn := b.Fn
+	nb := *b
+	nb.Fn = func(ps *env.ProgramState, args ...env.Object) env.Object {
+		res := fn(ps, args...)
+		if e, ok := res.(*env.Error); ok {
+			if nat, ok := e.Values["native"].(env.Native); ok {
+				if ae, ok := nat.Value.(*argError); ok && ae.name == "" {
+					ae.name = name
+					e.Message = ae.Error()
+				}
+			}
+		}
+		return res
+	}
+	return &nb
+}
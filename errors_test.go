@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/refaktor/rye/env"
+)
+
+func TestArgErrorToRye(t *testing.T) {
+	ps := testProgramState(t)
+	cause := &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}
+	err := &argError{pos: 2, name: "f", err: cause}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Error("the cause isn't unwrapped")
+	}
+	e := goErrorToRye(ps, err)
+	if typ := e.Values["go-type"].(env.String).Value; typ != "*fs.PathError" {
+		t.Errorf("got go-type %s, want *fs.PathError", typ)
+	}
+	if e.Parent == nil || e.Parent.Values["path"].(env.String).Value != "x" {
+		t.Error("the cause isn't the parent")
+	}
+}
+
+func TestGoErrorToRye(t *testing.T) {
+	ps := testProgramState(t)
+	err := fmt.Errorf("loading: %w", &fs.PathError{Op: "open", Path: "a.txt", Err: fs.ErrNotExist})
+	e := goErrorToRye(ps, err)
+	if e.Message != err.Error() {
+		t.Errorf("got message %q", e.Message)
+	}
+	path := e.Parent
+	if path == nil {
+		t.Fatal("no parent for the wrapped error")
+	}
+	for field, want := range map[string]string{"op": "open", "path": "a.txt", "go-type": "*fs.PathError"} {
+		if v, ok := path.Values[field].(env.String); !ok || v.Value != want {
+			t.Errorf("got %s %v, want %q", field, path.Values[field], want)
+		}
+	}
+	if path.Parent == nil || path.Parent.Message != fs.ErrNotExist.Error() {
+		t.Error("the cause of the path error isn't its parent")
+	}
+	if nat, ok := e.Values["native"].(env.Native); !ok || nat.Value != err {
+		t.Error("the error isn't kept as native")
+	}
+
+	link := goErrorToRye(ps, &os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.EEXIST})
+	for field, want := range map[string]string{"op": "rename", "old": "a", "new": "b"} {
+		if v, ok := link.Values[field].(env.String); !ok || v.Value != want {
+			t.Errorf("got %s %v, want %q", field, link.Values[field], want)
+		}
+	}
+	sc := goErrorToRye(ps, os.NewSyscallError("fsync", syscall.EIO))
+	if v, ok := sc.Values["syscall"].(env.String); !ok || v.Value != "fsync" {
+		t.Errorf("got syscall %v", sc.Values["syscall"])
+	}
+}
+
+func TestGoErrorFailures(t *testing.T) {
+	ps := testProgramState(t)
+	defer goErrorFailures.Store(false)
+	err := errors.New("failed")
+	isNative := func() bool {
+		obj, _ := conv_error_toRye(ps, nil, err)
+		_, ok := obj.(env.Native)
+		return ok
+	}
+
+	if err := flag.CommandLine.Set("go-error-failures", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if isNative() {
+		t.Error("got a native with -go-error-failures")
+	}
+	if err := flag.CommandLine.Set("go-error-failures", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if !isNative() {
+		t.Error("got no native without -go-error-failures")
+	}
+
+	evalTest(t, ps, `go-error-failures true`)
+	if isNative() {
+		t.Error("got a native after go-error-failures true")
+	}
+	evalTest(t, ps, `go-error-failures false`)
+	if !isNative() {
+		t.Error("got no native after go-error-failures false")
+	}
+}
@@ -0,0 +1,628 @@
+//go:build ignore
+
+// Gendocs writes godocs.gen.go, the Go declarations and doc comments of the
+// builtins ryegen made, which go\doc, go\methods and the REPL completion
+// show. It reads the builtins from ryegen_builtins_*.gen.go, so it runs
+// after ryegen:
+//
+//	go run gendocs.go
+//
+// The declarations are read from the sources of the packages, found with
+// go list.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const output = "godocs.gen.go"
+
+var fset = token.NewFileSet()
+
+// goDoc is the declaration and doc comment of a builtin.
+type goDoc struct {
+	Decl, Doc string
+}
+
+// declIndex are the declarations of a package by name, with methods and
+// fields under Type.Name.
+type declIndex struct {
+	funcs  map[string]*ast.FuncDecl
+	types  map[string]typeDecl
+	values map[string]valueSpec
+}
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	doc  *ast.CommentGroup
+	file *ast.File
+}
+
+type valueSpec struct {
+	spec *ast.ValueSpec
+	doc  *ast.CommentGroup
+	tok  token.Token
+}
+
+// builtinsFile are the builtins of a generated file.
+type builtinsFile struct {
+	// imports are the import paths by alias.
+	imports map[string]string
+	// kindPackages are the import paths by the package names in the kinds
+	// of natives, read from pkgLookup in the convs file.
+	kindPackages map[string]string
+	// maps are the builtins by map variable and name.
+	maps map[string]map[string]ast.Expr
+	// packages are the Rye package names by map variable.
+	packages map[string]string
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gendocs: ")
+	files, err := filepath.Glob("ryegen_builtins_*.gen.go")
+	if err != nil || len(files) == 0 {
+		log.Fatal("no ryegen_builtins_*.gen.go, run ryegen first")
+	}
+	var gens []*builtinsFile
+	paths := map[string]bool{}
+	for _, file := range files {
+		b, err := parseBuiltins(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gens = append(gens, b)
+		for _, p := range b.imports {
+			paths[p] = true
+		}
+	}
+	idx, err := loadPackages(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	docs := map[string]map[string]goDoc{}
+	for _, b := range gens {
+		for mapName, m := range b.maps {
+			pkg, ok := b.packages[mapName]
+			if !ok {
+				continue
+			}
+			if docs[pkg] == nil {
+				docs[pkg] = map[string]goDoc{}
+			}
+			for name, expr := range m {
+				if _, ok := docs[pkg][name]; ok {
+					continue
+				}
+				if kind, _, ok := strings.Cut(name, "//"); ok {
+					kindPkg, typ := kindType(kind)
+					if p, ok := b.kindPackages[kindPkg]; ok {
+						if d, ok := idx.typeDoc(p, typ); ok {
+							docs[pkg]["go("+kindPkg+"/"+typ+")"] = d
+						}
+					}
+				}
+				if d, ok := idx.builtinDoc(b.imports, expr); ok {
+					docs[pkg][name] = d
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gendocs.go; DO NOT EDIT.\n\npackage main\n\n")
+	buf.WriteString("var goDocs = map[string]map[string]goDoc{\n")
+	for _, pkg := range sortedKeys(docs) {
+		fmt.Fprintf(&buf, "%q: {\n", pkg)
+		for _, name := range sortedKeys(docs[pkg]) {
+			d := docs[pkg][name]
+			fmt.Fprintf(&buf, "%q: {%s, %s},\n", name, strconv.Quote(d.Decl), strconv.Quote(d.Doc))
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseBuiltins reads the builtins of a file generated by ryegen.
+func parseBuiltins(file string) (*builtinsFile, error) {
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	b := &builtinsFile{
+		imports:      map[string]string{},
+		kindPackages: map[string]string{},
+		maps:         map[string]map[string]ast.Expr{},
+		packages:     map[string]string{},
+	}
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			b.imports[imp.Name.Name] = p
+		}
+	}
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != "init" || fd.Body == nil {
+			continue
+		}
+		mapName := ""
+		for _, stmt := range fd.Body.List {
+			as, ok := stmt.(*ast.AssignStmt)
+			if !ok || len(as.Lhs) != 1 || len(as.Rhs) != 1 {
+				continue
+			}
+			if id, ok := as.Lhs[0].(*ast.Ident); ok && id.Name == "m" {
+				if rhs, ok := as.Rhs[0].(*ast.Ident); ok {
+					mapName = rhs.Name
+				}
+				continue
+			}
+			ix, ok := as.Lhs[0].(*ast.IndexExpr)
+			if !ok {
+				continue
+			}
+			key, ok := ix.Index.(*ast.BasicLit)
+			if !ok {
+				continue
+			}
+			name, _ := strconv.Unquote(key.Value)
+			switch m := ix.X.(type) {
+			case *ast.Ident:
+				if m.Name == "builtins" {
+					if rhs, ok := as.Rhs[0].(*ast.Ident); ok {
+						b.packages[rhs.Name] = name
+					}
+				} else if m.Name == "m" && mapName != "" {
+					if expr := builtinExpr(as.Rhs[0]); expr != nil {
+						if b.maps[mapName] == nil {
+							b.maps[mapName] = map[string]ast.Expr{}
+						}
+						b.maps[mapName][name] = expr
+					}
+				}
+			}
+		}
+	}
+	convs, err := parser.ParseFile(fset, strings.Replace(file, "_builtins_", "_convs_", 1), nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	ast.Inspect(convs, func(n ast.Node) bool {
+		as, ok := n.(*ast.AssignStmt)
+		if !ok || len(as.Lhs) != 1 || len(as.Rhs) != 1 {
+			return true
+		}
+		ix, ok := as.Lhs[0].(*ast.IndexExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := ix.X.(*ast.Ident); !ok || id.Name != "pkgLookup" {
+			return true
+		}
+		key, isKey := ix.Index.(*ast.BasicLit)
+		val, isVal := as.Rhs[0].(*ast.BasicLit)
+		if isKey && isVal {
+			p, _ := strconv.Unquote(key.Value)
+			name, _ := strconv.Unquote(val.Value)
+			b.kindPackages[name] = p
+		}
+		return false
+	})
+	return b, nil
+}
+
+// builtinExpr returns the Go value of mustBuiltin(conv(nil, nil, value)).
+func builtinExpr(expr ast.Expr) ast.Expr {
+	must, ok := expr.(*ast.CallExpr)
+	if !ok || len(must.Args) != 1 {
+		return nil
+	}
+	conv, ok := must.Args[0].(*ast.CallExpr)
+	if !ok || len(conv.Args) != 3 {
+		return nil
+	}
+	return conv.Args[2]
+}
+
+// kindType returns the package name and type name of a kind like
+// go(*widget/Button).
+func kindType(kind string) (pkg, typ string) {
+	kind = strings.TrimSuffix(strings.TrimPrefix(kind, "go("), ")")
+	kind = strings.TrimLeft(kind, "*")
+	pkg, typ, _ = strings.Cut(kind, "/")
+	return pkg, typ
+}
+
+// packageIndex are the declarations of packages by import path.
+type packageIndex map[string]*declIndex
+
+// loadPackages parses the sources of the packages.
+func loadPackages(paths map[string]bool) (packageIndex, error) {
+	args := []string{"list", "-json", "-e"}
+	for p := range paths {
+		args = append(args, p)
+	}
+	out, err := exec.Command("go", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	idx := packageIndex{}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var pkg struct {
+			ImportPath string
+			Dir        string
+			GoFiles    []string
+			CgoFiles   []string
+		}
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, err
+		}
+		d := &declIndex{
+			funcs:  map[string]*ast.FuncDecl{},
+			types:  map[string]typeDecl{},
+			values: map[string]valueSpec{},
+		}
+		for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+			f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+			if err != nil {
+				return nil, err
+			}
+			d.add(f)
+		}
+		idx[pkg.ImportPath] = d
+	}
+	return idx, nil
+}
+
+func (d *declIndex) add(f *ast.File) {
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) == 1 {
+				name = recvName(decl.Recv.List[0].Type) + "." + name
+			}
+			d.funcs[name] = decl
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc := spec.Doc
+					if doc == nil {
+						doc = decl.Doc
+					}
+					d.types[spec.Name.Name] = typeDecl{spec, doc, f}
+				case *ast.ValueSpec:
+					doc := spec.Doc
+					if doc == nil {
+						doc = spec.Comment
+					}
+					if doc == nil {
+						doc = decl.Doc
+					}
+					for _, n := range spec.Names {
+						d.values[n.Name] = valueSpec{spec, doc, decl.Tok}
+					}
+				}
+			}
+		}
+	}
+}
+
+// recvName returns the type name of a method receiver.
+func recvName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(expr.X)
+	case *ast.IndexExpr:
+		return recvName(expr.X)
+	case *ast.IndexListExpr:
+		return recvName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// builtinDoc returns the doc of the declaration the Go value of a builtin
+// comes from: a function, a method, a field, a variable or a type.
+func (idx packageIndex) builtinDoc(imports map[string]string, expr ast.Expr) (goDoc, bool) {
+	switch expr := expr.(type) {
+	case *ast.SelectorExpr:
+		switch x := ast.Unparen(expr.X).(type) {
+		case *ast.Ident:
+			return idx.topLevelDoc(imports[x.Name], expr.Sel.Name)
+		case *ast.StarExpr, *ast.SelectorExpr:
+			p, typ := typeRef(imports, x)
+			return idx.memberDoc(p, typ, expr.Sel.Name, 0)
+		}
+	case *ast.FuncLit:
+		params := expr.Type.Params.List
+		if len(params) == 0 {
+			return idx.valueRefDoc(imports, expr.Body)
+		}
+		if len(params[0].Names) == 0 {
+			return goDoc{}, false
+		}
+		p, typ := typeRef(imports, params[0].Type)
+		switch params[0].Names[0].Name {
+		case "s":
+			if f := fieldRef(expr.Body); f != "" {
+				return idx.memberDoc(p, typ, f, 0)
+			}
+		case "v":
+			return idx.typeDoc(p, typ)
+		case "x":
+			return idx.valueRefDoc(imports, expr.Body)
+		}
+	}
+	return goDoc{}, false
+}
+
+// typeRef returns the import path and name of a type like *alias.Name.
+func typeRef(imports map[string]string, expr ast.Expr) (string, string) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok {
+			return imports[x.Name], sel.Sel.Name
+		}
+	}
+	return "", ""
+}
+
+// fieldRef returns the field of s the body of a getter or setter uses.
+func fieldRef(body *ast.BlockStmt) string {
+	if len(body.List) == 0 {
+		return ""
+	}
+	var expr ast.Expr
+	switch stmt := body.List[0].(type) {
+	case *ast.ReturnStmt:
+		if len(stmt.Results) == 1 {
+			expr = stmt.Results[0]
+		}
+	case *ast.AssignStmt:
+		expr = stmt.Lhs[0]
+	}
+	if u, ok := expr.(*ast.UnaryExpr); ok {
+		expr = u.X
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok && x.Name == "s" {
+			return sel.Sel.Name
+		}
+	}
+	return ""
+}
+
+// valueRefDoc returns the doc of the package variable or constant the body
+// of a getter or setter uses.
+func (idx packageIndex) valueRefDoc(imports map[string]string, body *ast.BlockStmt) (goDoc, bool) {
+	if len(body.List) == 0 {
+		return goDoc{}, false
+	}
+	var expr ast.Expr
+	switch stmt := body.List[0].(type) {
+	case *ast.ReturnStmt:
+		if len(stmt.Results) == 1 {
+			expr = stmt.Results[0]
+		}
+	case *ast.AssignStmt:
+		expr = stmt.Lhs[0]
+	}
+	if u, ok := expr.(*ast.UnaryExpr); ok {
+		expr = u.X
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok {
+			return idx.topLevelDoc(imports[x.Name], sel.Sel.Name)
+		}
+	}
+	return goDoc{}, false
+}
+
+// topLevelDoc returns the doc of a function, variable, constant or type of a
+// package.
+func (idx packageIndex) topLevelDoc(pkgPath, name string) (goDoc, bool) {
+	d, ok := idx[pkgPath]
+	if !ok {
+		return goDoc{}, false
+	}
+	if fd, ok := d.funcs[name]; ok {
+		return goDoc{funcDecl(fd), docText(fd.Doc)}, true
+	}
+	if v, ok := d.values[name]; ok {
+		return goDoc{valueDecl(v, name), docText(v.doc)}, true
+	}
+	return idx.typeDoc(pkgPath, name)
+}
+
+// typeDoc returns the doc of a type.
+func (idx packageIndex) typeDoc(pkgPath, name string) (goDoc, bool) {
+	d, ok := idx[pkgPath]
+	if !ok {
+		return goDoc{}, false
+	}
+	t, ok := d.types[name]
+	if !ok {
+		return goDoc{}, false
+	}
+	decl := "type " + name
+	switch t.spec.Type.(type) {
+	case *ast.StructType:
+		decl += " struct"
+	case *ast.InterfaceType:
+		decl += " interface"
+	default:
+		if t.spec.Assign.IsValid() {
+			decl += " ="
+		}
+		decl += " " + nodeString(t.spec.Type)
+	}
+	return goDoc{decl, docText(t.doc)}, true
+}
+
+// memberDoc returns the doc of a method or field of a type, looking in the
+// types it embeds if the type doesn't declare it.
+func (idx packageIndex) memberDoc(pkgPath, typ, name string, depth int) (goDoc, bool) {
+	d, ok := idx[pkgPath]
+	if !ok || depth > 5 {
+		return goDoc{}, false
+	}
+	if fd, ok := d.funcs[typ+"."+name]; ok {
+		return goDoc{funcDecl(fd), docText(fd.Doc)}, true
+	}
+	t, ok := d.types[typ]
+	if !ok {
+		return goDoc{}, false
+	}
+	var fields []*ast.Field
+	switch st := t.spec.Type.(type) {
+	case *ast.StructType:
+		fields = st.Fields.List
+	case *ast.InterfaceType:
+		fields = st.Methods.List
+	}
+	for _, f := range fields {
+		for _, n := range f.Names {
+			if n.Name != name {
+				continue
+			}
+			doc := f.Doc
+			if doc == nil {
+				doc = f.Comment
+			}
+			if ft, ok := f.Type.(*ast.FuncType); ok {
+				if _, ok := t.spec.Type.(*ast.InterfaceType); ok {
+					return goDoc{"func (" + typ + ") " + name + strings.TrimPrefix(nodeString(ft), "func"), docText(doc)}, true
+				}
+			}
+			return goDoc{typ + "." + name + " " + nodeString(f.Type), docText(doc)}, true
+		}
+	}
+	for _, f := range fields {
+		if len(f.Names) > 0 {
+			continue
+		}
+		p, embedded := pkgPath, ""
+		switch e := f.Type.(type) {
+		case *ast.StarExpr:
+			embedded = recvName(e.X)
+			if sel, ok := e.X.(*ast.SelectorExpr); ok {
+				p, embedded = importedType(t.file, sel)
+			}
+		case *ast.Ident:
+			embedded = e.Name
+		case *ast.SelectorExpr:
+			p, embedded = importedType(t.file, e)
+		}
+		if embedded == "" {
+			continue
+		}
+		if embedded == name {
+			doc := f.Doc
+			if doc == nil {
+				doc = f.Comment
+			}
+			return goDoc{typ + "." + name + " " + nodeString(f.Type), docText(doc)}, true
+		}
+		if doc, ok := idx.memberDoc(p, embedded, name, depth+1); ok {
+			return doc, true
+		}
+	}
+	return goDoc{}, false
+}
+
+// importedType returns the import path and name of a type like pkg.Name
+// in a file. The package name is taken to be the last element of the import
+// path that isn't a major version, like fyne for fyne.io/fyne/v2.
+func importedType(f *ast.File, sel *ast.SelectorExpr) (string, string) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", ""
+	}
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(p)
+		if v := strings.TrimPrefix(name, "v"); v != name && strings.Trim(v, "0123456789") == "" {
+			name = path.Base(path.Dir(p))
+		}
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == x.Name {
+			return p, sel.Sel.Name
+		}
+	}
+	return "", ""
+}
+
+// funcDecl returns the declaration of a function without its body.
+func funcDecl(fd *ast.FuncDecl) string {
+	decl := *fd
+	decl.Doc = nil
+	decl.Body = nil
+	return nodeString(&decl)
+}
+
+// valueDecl returns the declaration of a variable or constant.
+func valueDecl(v valueSpec, name string) string {
+	decl := v.tok.String() + " " + name
+	if v.spec.Type != nil {
+		decl += " " + nodeString(v.spec.Type)
+	}
+	for i, n := range v.spec.Names {
+		if n.Name == name && i < len(v.spec.Values) {
+			if val := nodeString(v.spec.Values[i]); len(val) <= 60 && !strings.Contains(val, "\n") {
+				decl += " = " + val
+			}
+		}
+	}
+	return decl
+}
+
+func nodeString(node any) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
+
+func docText(doc *ast.CommentGroup) string {
+	return strings.TrimSpace(doc.Text())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
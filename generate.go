@@ -1,3 +1,5 @@
 package main
 
 //go:generate go tool ryegen -q
+//go:generate go run postgen.go
+//go:generate go run gendocs.go
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// Scripts get the Go packages they use with import\go. Besides the name of
+// one package, it takes a block of names and returns a context with the
+// package contexts under their short names, the last element of the name,
+// and import\go\all sets the short names of all Fyne packages in the current
+// context, so a script can start with one line:
+//
+//	import\go\all
+//	w: app/new .window "Hello"
+//	w .set-content widget/label "Hello"
+//
+// The context of a package is made when it is first imported in a program,
+// instead of for all packages on start, which is most of the builtins a small
+// script never uses. The methods of the types are registered on start, as
+// values of a type can come from any package.
+
+func init() {
+	overrideBuiltins["import\\go"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the context of a Go package, or for a block of package names a context of their contexts under their short names.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			switch arg := args[0].(type) {
+			case env.String:
+				pkg, ok := packageContext(ps, arg.Value)
+				if !ok {
+					ps.FailureFlag = true
+					return env.NewError("unknown Go package \"" + arg.Value + "\"")
+				}
+				return *pkg
+			case env.Block:
+				var names []string
+				for _, obj := range arg.Series.S {
+					name, ok := obj.(env.String)
+					if !ok {
+						ps.FailureFlag = true
+						return env.NewError("expected package name string, but got " + objectType(ps, obj))
+					}
+					names = append(names, name.Value)
+				}
+				ctx, err := packagesContext(ps, names)
+				if err != nil {
+					ps.FailureFlag = true
+					return err
+				}
+				return *ctx
+			}
+			ps.FailureFlag = true
+			return env.NewError("expected package name string or block, but got " + objectType(ps, args[0]))
+		},
+	}
+
+	baseBuiltins["import\\go\\all"] = &env.VarBuiltin{
+		Argsn: 0,
+		Doc:   "Sets the words of all Fyne packages, like app and widget, in the current context. Words that are set already are left as they are.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			ctx, _ := packagesContext(ps, fynePackages())
+			for _, name := range fynePackages() {
+				idx := ps.Idx.IndexWord(packageShortName(name))
+				if _, ok := ps.Ctx.GetCurrent(idx); !ok {
+					pkg, _ := ctx.GetCurrent(idx)
+					ps.Ctx.Set(idx, pkg)
+				}
+			}
+			return *ctx
+		},
+	}
+}
+
+// registerMethods registers the methods of the types of the Go packages.
+// main calls it on start, instead of making the contexts of all packages.
+func registerMethods(ps *env.ProgramState) {
+	for pkg := range builtins {
+		evaldo.RegisterVarBuiltins2(packageBuiltins(pkg, true), ps, "gopkg("+pkg+")")
+	}
+}
+
+// packageContext returns the context of a Go package in the program of ps,
+// making it on the first import.
+func packageContext(ps *env.ProgramState, name string) (*env.RyeCtx, bool) {
+	if _, ok := builtins[name]; !ok {
+		return nil, false
+	}
+	root := rootContext(ps.Ctx)
+	word := "gopkg(" + name + ")"
+	if obj, ok := root.GetCurrent(ps.Idx.IndexWord(word)); ok {
+		if ctx, ok := obj.(env.RyeCtx); ok {
+			return &ctx, true
+		}
+	}
+	ctx := ps.Ctx
+	ps.Ctx = root
+	pkg := builtinsContext(ps, packageBuiltins(name, false), word)
+	ps.Ctx = ctx
+	return pkg, true
+}
+
+// packageBuiltins returns the methods of the builtins of a package, or the
+// others, named for their errors.
+func packageBuiltins(pkg string, methods bool) map[string]*env.VarBuiltin {
+	res := make(map[string]*env.VarBuiltin, len(builtins[pkg]))
+	for name, b := range builtins[pkg] {
+		if strings.Contains(name, "//") == methods {
+			res[name] = namedBuiltin(builtinName(pkg, name), b)
+		}
+	}
+	return res
+}
+
+// builtinName returns the name of a builtin of a package as scripts call it,
+// like container/border, or like widget/Button.set-text for a method.
+func builtinName(pkg, name string) string {
+	if kind, method, ok := strings.Cut(name, "//"); ok {
+		typ := strings.TrimPrefix(strings.TrimSuffix(kind, ")"), "go(")
+		return strings.TrimLeft(typ, "*") + "." + method
+	}
+	return packageShortName(pkg) + "/" + name
+}
+
+// packagesContext returns a context with the contexts of the packages under
+// their short names.
+func packagesContext(ps *env.ProgramState, names []string) (*env.RyeCtx, *env.Error) {
+	ctx := env.NewEnv(nil)
+	for _, name := range names {
+		pkg, ok := packageContext(ps, name)
+		if !ok {
+			return nil, env.NewError("unknown Go package \"" + name + "\"")
+		}
+		ctx.Set(ps.Idx.IndexWord(packageShortName(name)), *pkg)
+	}
+	return ctx, nil
+}
+
+// fynePackages returns the names of the Fyne packages, sorted.
+func fynePackages() []string {
+	var names []string
+	for name := range builtins {
+		if name == "fyne" || strings.HasPrefix(name, "fyne/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// packageShortName returns the word a package is imported as, like widget
+// for fyne/widget.
+func packageShortName(name string) string {
+	return path.Base(name)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestImportGoBlock(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		pkgs: import\go { "fyne/widget" "fyne/container" }
+		l: pkgs/widget/label "Hi"
+	`)
+	obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("pkgs"))
+	pkgs, ok := obj.(env.RyeCtx)
+	if !ok {
+		t.Fatalf("got %s, want a context", objectType(ps, obj))
+	}
+	if n := len(pkgs.GetState()); n != 2 {
+		t.Errorf("got %d words in the context, want 2", n)
+	}
+	for _, name := range []string{"widget", "container"} {
+		pkg, ok := pkgs.GetCurrent(ps.Idx.IndexWord(name))
+		if _, isCtx := pkg.(env.RyeCtx); !ok || !isCtx {
+			t.Errorf("got %s under %s, want the package context", objectType(ps, pkg), name)
+		}
+	}
+	if l := testValue[*widget.Label](t, ps, "l"); l.Text != "Hi" {
+		t.Errorf("got label %q, want \"Hi\"", l.Text)
+	}
+}
+
+func TestImportGoAll(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		widget: "mine"
+		import\go\all
+	`)
+	if obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("widget")); obj != *env.NewString("mine") {
+		t.Errorf("got widget %s, want the string set before", objectType(ps, obj))
+	}
+	for _, name := range []string{"app", "container", "layout"} {
+		obj, _ := ps.Ctx.GetCurrent(ps.Idx.IndexWord(name))
+		if _, ok := obj.(env.RyeCtx); !ok {
+			t.Errorf("got %s %s, want the package context", name, objectType(ps, obj))
+		}
+	}
+}
+
+func TestImportGoErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"unknown package", `import\go "fyne/nothing"`, `unknown Go package "fyne/nothing"`},
+		{"unknown package in block", `import\go { "fyne/widget" "nothing" }`, `unknown Go package "nothing"`},
+		{"not a name", `import\go { "fyne/widget" 1 }`, "expected package name string, but got [Integer: 1]"},
+		{"not a name or block", `import\go 1`, "expected package name string or block, but got [Integer: 1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := evalTest(t, testProgramState(t), `err: try { `+tt.src+` }`)
+			obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("err"))
+			if e, ok := obj.(*env.Error); !ok || e.Message != tt.want {
+				t.Errorf("got %s, want the error %q", obj.Inspect(*ps.Idx), tt.want)
+			}
+		})
+	}
+}
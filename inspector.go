@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+// The inspector shows the tree of canvas objects of a window, including the
+// objects widgets are drawn with and the overlays like dialogs. For the
+// selected object it shows the type, position, size and minimum size,
+// highlights it on the window for a moment, and evaluates Rye code with the
+// object injected, like `.text?` or `.hide`. It is opened with fyne/inspect,
+// and toggled with Ctrl+Shift+I in windows opened with app .window.
+
+// inspectShortcut toggles the inspector of a window.
+var inspectShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyI, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}
+
+// inspectors are the open inspectors by the window they inspect. The
+// inspector builtin and the shortcut can be called from different
+// goroutines, so inspectorsMu guards them.
+var (
+	inspectorsMu sync.Mutex
+	inspectors   = map[fyne.Window]*inspector{}
+)
+
+func init() {
+	builtins_fyne["inspect"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Opens the inspector of the objects of a window.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			w, err := conv_fyne_io_fyne_v2_Window_fromRye(ps, nil, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			openInspector(ps, w)
+			return args[0]
+		},
+	}
+}
+
+// addInspectShortcut adds the inspector shortcut to the windows opened with
+// app .window. It wraps the generated builtin, so it is called once the
+// builtins are registered.
+func addInspectShortcut() {
+	m := builtins_fyne
+	newWindow := m["go(fyne/App)//window"]
+	m["go(fyne/App)//window"] = &env.VarBuiltin{
+		Argsn: newWindow.Argsn,
+		Doc:   newWindow.Doc,
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			res := newWindow.Fn(ps, args...)
+			if nat, ok := res.(env.Native); ok {
+				if w, ok := nat.Value.(fyne.Window); ok {
+					w.Canvas().AddShortcut(inspectShortcut, func(fyne.Shortcut) {
+						toggleInspector(ps, w)
+					})
+				}
+			}
+			return res
+		},
+	}
+}
+
+func toggleInspector(ps *env.ProgramState, w fyne.Window) {
+	inspectorsMu.Lock()
+	in, ok := inspectors[w]
+	inspectorsMu.Unlock()
+	if ok {
+		in.window.Close()
+		return
+	}
+	openInspector(ps, w)
+}
+
+// inspector is the inspector window of the target window. Code is evaluated
+// in ps.
+type inspector struct {
+	ps       *env.ProgramState
+	target   fyne.Window
+	window   fyne.Window
+	tree     *widget.Tree
+	objects  map[widget.TreeNodeID]fyne.CanvasObject
+	selected fyne.CanvasObject
+	details  map[string]*widget.Label
+	result   *widget.Label
+	// highlight is the overlay of the target that highlights the selected
+	// object. As overlays take the input of a window, it is only shown for a
+	// moment.
+	highlight fyne.CanvasObject
+}
+
+// inspectorDetails are the details shown of the selected object.
+var inspectorDetails = []string{"Type", "Go type", "Position", "Absolute position", "Size", "Min size", "Visible"}
+
+func openInspector(ps *env.ProgramState, w fyne.Window) {
+	inspectorsMu.Lock()
+	if in, ok := inspectors[w]; ok {
+		inspectorsMu.Unlock()
+		in.window.RequestFocus()
+		return
+	}
+	in := &inspector{
+		ps:      ps,
+		target:  w,
+		objects: map[widget.TreeNodeID]fyne.CanvasObject{},
+		details: map[string]*widget.Label{},
+	}
+	in.window = fyne.CurrentApp().NewWindow("Inspector - " + w.Title())
+	in.window.SetContent(in.content())
+	in.window.Resize(fyne.NewSize(800, 500))
+	in.window.SetOnClosed(func() {
+		in.hideHighlight()
+		inspectorsMu.Lock()
+		delete(inspectors, w)
+		inspectorsMu.Unlock()
+	})
+	inspectors[w] = in
+	inspectorsMu.Unlock()
+	in.window.Show()
+}
+
+func (in *inspector) content() fyne.CanvasObject {
+	in.tree = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			var ids []widget.TreeNodeID
+			for _, o := range in.children(id) {
+				cid := fmt.Sprintf("%p", o)
+				in.objects[cid] = o
+				ids = append(ids, cid)
+			}
+			return ids
+		},
+		func(id widget.TreeNodeID) bool {
+			return len(in.children(id)) > 0
+		},
+		func(bool) fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TreeNodeID, _ bool, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(objectSummary(in.objects[id]))
+		},
+	)
+	in.tree.OnSelected = func(id widget.TreeNodeID) {
+		in.selected = in.objects[id]
+		in.showDetails()
+		in.flashHighlight()
+	}
+
+	form := container.New(layout.NewFormLayout())
+	for _, name := range inspectorDetails {
+		in.details[name] = widget.NewLabel("")
+		form.Add(widget.NewLabelWithStyle(name, fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}))
+		form.Add(in.details[name])
+	}
+	code := widget.NewEntry()
+	code.SetPlaceHolder("Rye code, like .text? or .hide")
+	code.OnSubmitted = in.eval
+	in.result = widget.NewLabel("")
+	in.result.Wrapping = fyne.TextWrapWord
+	toolbar := container.NewHBox(
+		widget.NewButton("Refresh", in.refresh),
+		widget.NewButton("Highlight", in.flashHighlight),
+	)
+	details := container.NewBorder(
+		container.NewVBox(toolbar, form, container.NewBorder(nil, nil, nil, widget.NewButton("Eval", func() { in.eval(code.Text) }), code)),
+		nil, nil, nil,
+		container.NewVScroll(in.result),
+	)
+	split := container.NewHSplit(in.tree, details)
+	split.Offset = 0.5
+	return split
+}
+
+// children returns the objects in the object with the tree ID, or the
+// content and overlays of the target for the root. The objects of widgets
+// come from test.WidgetRenderer, through objectChildren, as the inspector
+// has to show the objects that are drawn.
+func (in *inspector) children(id widget.TreeNodeID) []fyne.CanvasObject {
+	if id == "" {
+		return in.roots()
+	}
+	if o, ok := in.objects[id]; ok {
+		return objectChildren(o)
+	}
+	return nil
+}
+
+// roots returns the content and overlays of the target, except the
+// highlight.
+func (in *inspector) roots() []fyne.CanvasObject {
+	c := in.target.Canvas()
+	roots := []fyne.CanvasObject{c.Content()}
+	for _, o := range c.Overlays().List() {
+		if o != in.highlight {
+			roots = append(roots, o)
+		}
+	}
+	return roots
+}
+
+// refresh reads the objects of the target again.
+func (in *inspector) refresh() {
+	in.objects = map[widget.TreeNodeID]fyne.CanvasObject{}
+	in.tree.Refresh()
+	in.showDetails()
+}
+
+func (in *inspector) showDetails() {
+	o := in.selected
+	if o == nil {
+		for _, l := range in.details {
+			l.SetText("")
+		}
+		return
+	}
+	in.details["Type"].SetText(nativeKind(in.ps, o))
+	in.details["Go type"].SetText(fmt.Sprintf("%T", o))
+	in.details["Position"].SetText(formatPair(o.Position().X, o.Position().Y))
+	if pos, ok := objectPosition(in.roots(), o, fyne.Position{}); ok {
+		in.details["Absolute position"].SetText(formatPair(pos.X, pos.Y))
+	} else {
+		in.details["Absolute position"].SetText("not in the window")
+	}
+	in.details["Size"].SetText(formatPair(o.Size().Width, o.Size().Height))
+	in.details["Min size"].SetText(formatPair(o.MinSize().Width, o.MinSize().Height))
+	in.details["Visible"].SetText(strconv.FormatBool(o.Visible()))
+}
+
+// flashHighlight highlights the selected object on the target for a moment.
+func (in *inspector) flashHighlight() {
+	in.hideHighlight()
+	o := in.selected
+	if o == nil || !o.Visible() {
+		return
+	}
+	pos, ok := objectPosition(in.roots(), o, fyne.Position{})
+	if !ok {
+		return
+	}
+	frame := canvas.NewRectangle(color.Transparent)
+	frame.StrokeColor = color.NRGBA{R: 0xff, G: 0x30, B: 0x30, A: 0xff}
+	frame.StrokeWidth = 2
+	frame.Move(pos)
+	frame.Resize(o.Size())
+	highlight := container.NewWithoutLayout(frame)
+	highlight.Resize(in.target.Canvas().Size())
+	in.highlight = highlight
+	in.target.Canvas().Overlays().Add(highlight)
+	time.AfterFunc(time.Second, func() {
+		fyne.Do(func() {
+			if in.highlight == highlight {
+				in.hideHighlight()
+			}
+		})
+	})
+}
+
+func (in *inspector) hideHighlight() {
+	if in.highlight != nil {
+		in.target.Canvas().Overlays().Remove(in.highlight)
+		in.highlight = nil
+	}
+}
+
+// eval evaluates code with the selected object injected and shows the
+// result.
+func (in *inspector) eval(code string) {
+	if in.selected == nil {
+		in.result.SetText("Select an object first.")
+		return
+	}
+	obj, _ := conv_fyne_io_fyne_v2_CanvasObject_toRye(in.ps, nil, in.selected)
+	in.result.SetText(evalCode(in.ps, code, obj))
+	in.showDetails()
+	in.tree.Refresh()
+}
+
+// objectPosition returns the position of o in the objects at offset.
+func objectPosition(objs []fyne.CanvasObject, o fyne.CanvasObject, offset fyne.Position) (fyne.Position, bool) {
+	for _, x := range objs {
+		pos := offset.Add(x.Position())
+		if x == o {
+			return pos, true
+		}
+		if p, ok := objectPosition(objectChildren(x), o, pos); ok {
+			return p, true
+		}
+	}
+	return fyne.Position{}, false
+}
+
+// objectSummary describes an object in the tree by its Go type and text.
+func objectSummary(o fyne.CanvasObject) string {
+	if o == nil {
+		return ""
+	}
+	s := fmt.Sprintf("%T", o)
+	if text, ok := objectText(o); ok && text != "" {
+		s += " " + strconv.Quote(text)
+	}
+	if !o.Visible() {
+		s += " (hidden)"
+	}
+	return s
+}
+
+// nativeKind returns the type of the native o is in Rye.
+func nativeKind(ps *env.ProgramState, o fyne.CanvasObject) string {
+	obj, _ := conv_fyne_io_fyne_v2_CanvasObject_toRye(ps, nil, o)
+	if nat, ok := obj.(env.Native); ok {
+		return ps.Idx.GetWord(nat.Kind.Index)
+	}
+	return objectType(ps, obj)
+}
+
+func formatPair(a, b float32) string {
+	return strconv.FormatFloat(float64(a), 'f', -1, 32) + ", " + strconv.FormatFloat(float64(b), 'f', -1, 32)
+}
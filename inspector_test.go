@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestToggleInspector(t *testing.T) {
+	ps := testProgramState(t)
+	w := test.NewWindow(widget.NewLabel("l"))
+	defer w.Close()
+	count := func() int {
+		inspectorsMu.Lock()
+		defer inspectorsMu.Unlock()
+		return len(inspectors)
+	}
+
+	windows := len(fyne.CurrentApp().Driver().AllWindows())
+	openInspector(ps, w)
+	openInspector(ps, w)
+	if n := count(); n != 1 {
+		t.Errorf("got %d inspectors, want 1", n)
+	}
+	if n := len(fyne.CurrentApp().Driver().AllWindows()); n != windows+1 {
+		t.Errorf("got %d windows, want %d", n, windows+1)
+	}
+	toggleInspector(ps, w)
+	if n := count(); n != 0 {
+		t.Errorf("got %d inspectors after closing, want 0", n)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/refaktor/rye/env"
+)
+
+// Natives only print the Go type they hold, so scripts ask about them:
+//
+//	b: widget/button "Hi" does { }
+//	go\type b             ; "go(*widget/Button)"
+//	go\methods b          ; { "cursor" "disable" ... "set-text" ... }
+//	go\fields b           ; { "alignment" "icon" ... "text" }
+//	go\doc "widget/button"
+//	go\doc "widget/Button.set-text"
+//
+// The declarations and doc comments come from godocs.gen.go, which gendocs.go
+// writes from the Go sources after ryegen. The builtins are go\ and not go/
+// words, as go runs a function in a goroutine.
+
+// goDoc is the Go declaration and doc comment of a builtin.
+type goDoc struct {
+	Decl, Doc string
+}
+
+// String returns the declaration and the comment, as go doc shows them.
+func (d goDoc) String() string {
+	if d.Doc == "" {
+		return d.Decl
+	}
+	return d.Decl + "\n\n" + d.Doc
+}
+
+func init() {
+	baseBuiltins["go\\type"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the Go type of a native value, named as the kinds of natives, like go(*widget/Button).",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			nat, ok := args[0].(env.Native)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected native, but got " + objectType(ps, args[0]))
+			}
+			// Values of unexported types, like the bindings fyne returns,
+			// are named by the kind they were made with.
+			if name, ok := nativeName(reflect.TypeOf(nat.Value)); ok {
+				return *env.NewString(name)
+			}
+			return *env.NewString(ps.Idx.GetWord(nat.Kind.Index))
+		},
+	}
+
+	baseBuiltins["go\\methods"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the names of the Go methods of a native value.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			nat, ok := args[0].(env.Native)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected native, but got " + objectType(ps, args[0]))
+			}
+			methods, _ := nativeMembers(ps, nat)
+			return stringsBlock(methods)
+		},
+	}
+
+	baseBuiltins["go\\fields"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the names of the fields of a native Go struct, which have a getter ending in ? and a setter ending in !.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			nat, ok := args[0].(env.Native)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected native, but got " + objectType(ps, args[0]))
+			}
+			_, fields := nativeMembers(ps, nat)
+			return stringsBlock(fields)
+		},
+	}
+
+	baseBuiltins["go\\doc"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the Go declaration and doc comment of a builtin like \"widget/button\", a type like \"widget/Button\", one of its methods or fields like \"widget/Button.set-text\", or the type of a native value.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			switch arg := args[0].(type) {
+			case env.String:
+				if d, ok := lookupDoc(arg.Value); ok {
+					return *env.NewString(d.String())
+				}
+				ps.FailureFlag = true
+				return env.NewError("no Go doc for \"" + arg.Value + "\"")
+			case env.Native:
+				if d, ok := kindDoc(ps.Idx.GetWord(arg.Kind.Index), ""); ok {
+					return *env.NewString(d.String())
+				}
+				ps.FailureFlag = true
+				return env.NewError("no Go doc for " + arg.Inspect(*ps.Idx))
+			}
+			ps.FailureFlag = true
+			return env.NewError("expected string or native, but got " + objectType(ps, args[0]))
+		},
+	}
+}
+
+// nativeMembers returns the names of the methods and fields registered for
+// the kind of nat, sorted.
+func nativeMembers(ps *env.ProgramState, nat env.Native) (methods, fields []string) {
+	seen := map[string]bool{}
+	for _, idx := range ps.Gen.GetMethods(nat.Kind.Index) {
+		name := ps.Idx.GetWord(idx)
+		field := strings.TrimRight(name, "?!")
+		switch {
+		case field == name:
+			methods = append(methods, name)
+		case !seen[field]:
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(methods)
+	sort.Strings(fields)
+	return methods, fields
+}
+
+func stringsBlock(items []string) env.Block {
+	objs := make([]env.Object, len(items))
+	for i, s := range items {
+		objs[i] = *env.NewString(s)
+	}
+	return *env.NewBlock(*env.NewTSeries(objs))
+}
+
+// lookupDoc returns the doc of pkg/name, pkg/Type or pkg/Type.member, where
+// pkg is the name of a Go package or its short name.
+func lookupDoc(path string) (goDoc, bool) {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return goDoc{}, false
+	}
+	pkg, ok := resolvePackage(path[:i])
+	if !ok {
+		return goDoc{}, false
+	}
+	name := path[i+1:]
+	if typ, member, ok := strings.Cut(name, "."); ok {
+		return kindDoc("go("+packageShortName(pkg)+"/"+typ+")", member)
+	}
+	if d, ok := goDocs[pkg][name]; ok {
+		return d, true
+	}
+	return kindDoc("go("+packageShortName(pkg)+"/"+name+")", "")
+}
+
+// kindDoc returns the doc of the type of a kind like
+// go(*widget/Button), or of its method or field member.
+func kindDoc(kind, member string) (goDoc, bool) {
+	typ := strings.TrimPrefix(strings.TrimSuffix(kind, ")"), "go(")
+	typ = strings.TrimLeft(typ, "*")
+	pkg, _, _ := strings.Cut(typ, "/")
+	pkg, _ = resolvePackage(pkg)
+	docs := goDocs[pkg]
+	if member == "" {
+		d, ok := docs["go("+typ+")"]
+		return d, ok
+	}
+	for _, k := range []string{"go(*" + typ + ")", "go(" + typ + ")"} {
+		for _, name := range []string{member, member + "?", member + "!"} {
+			if d, ok := docs[k+"//"+name]; ok {
+				return d, true
+			}
+		}
+	}
+	return goDoc{}, false
+}
+
+// resolvePackage returns the Go package called name or, if there is none,
+// the one with name as its short name, like fyne/widget for widget.
+func resolvePackage(name string) (string, bool) {
+	if _, ok := builtins[name]; ok {
+		return name, true
+	}
+	for _, pkg := range append(fynePackages(), sortedPackages()...) {
+		if packageShortName(pkg) == name {
+			return pkg, true
+		}
+	}
+	return "", false
+}
+
+// sortedPackages returns the names of all Go packages, sorted.
+func sortedPackages() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
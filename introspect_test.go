@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/refaktor/rye/env"
+)
+
+func TestGoIntrospection(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		b: widget/button "Hi" fn { } { }
+		type: go\type b
+		methods: go\methods b
+		fields: go\fields b
+		doc: go\doc b
+	`)
+	str := func(word string) string {
+		obj, _ := ps.Ctx.Get(ps.Idx.IndexWord(word))
+		s, ok := obj.(env.String)
+		if !ok {
+			t.Fatalf("got %s %s, want a string", word, objectType(ps, obj))
+		}
+		return s.Value
+	}
+	strs := func(word string) []string {
+		obj, _ := ps.Ctx.Get(ps.Idx.IndexWord(word))
+		blk, ok := obj.(env.Block)
+		if !ok {
+			t.Fatalf("got %s %s, want a block", word, objectType(ps, obj))
+		}
+		var s []string
+		for _, item := range blk.Series.GetAll() {
+			s = append(s, item.(env.String).Value)
+		}
+		return s
+	}
+
+	if got := str("type"); got != "go(*widget/Button)" {
+		t.Errorf("got type %q, want \"go(*widget/Button)\"", got)
+	}
+	methods := strs("methods")
+	for _, m := range []string{"set-text", "tapped", "disable"} {
+		if !slices.Contains(methods, m) {
+			t.Errorf("got methods %q, want %s among them", methods, m)
+		}
+	}
+	if !slices.IsSorted(methods) || slices.Contains(methods, "text?") {
+		t.Errorf("got methods %q, want them sorted and without getters", methods)
+	}
+	if fields := strs("fields"); !slices.Contains(fields, "text") || !slices.Contains(fields, "importance") {
+		t.Errorf("got fields %q, want text and importance among them", fields)
+	}
+	if got := str("doc"); !strings.HasPrefix(got, "type Button struct") {
+		t.Errorf("got doc of the native %q, want the type Button", got)
+	}
+}
+
+func TestGoDoc(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `import\go\all`)
+	tests := []struct {
+		name, want string
+	}{
+		{"widget/button", "func NewButton(label string, tapped func()) *Button"},
+		{"fyne/widget/button", "func NewButton("},
+		{"widget/Button", "type Button struct"},
+		{"widget/Button.set-text", "func (b *Button) SetText(text string)"},
+		{"widget/Button.text", "Text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := evalTest(t, ps, `go\doc "`+tt.name+`"`).Res
+			s, ok := res.(env.String)
+			if !ok || !strings.Contains(s.Value, tt.want) {
+				t.Errorf("got %s, want %q", res.Inspect(*ps.Idx), tt.want)
+			}
+		})
+	}
+	for _, name := range []string{"widget/nothing", "widget/Button.nothing", "nothing/Button", "button"} {
+		if _, ok := lookupDoc(name); ok {
+			t.Errorf("got a doc for %q", name)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// The layout dialect describes a user interface as a block:
+//
+//	fyne/layout {
+//		form {
+//			"Name:" entry :name-entry
+//			"Mood:" select [ "Happy" "Sad" ] fn { x } { } |place-holder "Pick one"
+//		}
+//		hbox { button "Save" { save } |importance 'high  button "Cancel" { w .close } }
+//	}
+//
+// A word naming a constructor of fyne/widget creates that widget, taking as
+// many arguments as the constructor does. A curly block in argument position
+// becomes a function without arguments, everything else is evaluated as a
+// Rye expression. A string on its own is a label. Containers take a block of
+// elements. Words and expressions that evaluate to canvas objects can be used
+// as elements too.
+//
+// A set-word before an element or a left set-word after it sets the word to
+// the element, and gives the element that name for test/find-by-name. A
+// pipe-word after an element calls the setter or method of that name on it:
+// `|importance 'high` calls .importance! or, if there is no such field,
+// .set-importance or .importance.
+
+// uiElement is an element of a layout block, with the border slot it was
+// placed into.
+type uiElement struct {
+	obj  fyne.CanvasObject
+	slot string
+}
+
+// uiContainer builds a container from the arguments following its word.
+type uiContainer func(b *uiBuilder) (fyne.CanvasObject, error)
+
+// uiContainers are the containers of the dialect.
+var uiContainers map[string]uiContainer
+
+// uiBox returns a container taking a block of elements.
+func uiBox[T fyne.CanvasObject](fn func(objs ...fyne.CanvasObject) T) uiContainer {
+	return func(b *uiBuilder) (fyne.CanvasObject, error) {
+		objs, err := b.children(nil)
+		if err != nil {
+			return nil, err
+		}
+		return fn(objs...), nil
+	}
+}
+
+// uiSingle returns a container wrapping one element. A block of more
+// elements is put into a vbox.
+func uiSingle[T fyne.CanvasObject](fn func(obj fyne.CanvasObject) T) uiContainer {
+	return uiBox(func(objs ...fyne.CanvasObject) T {
+		if len(objs) == 1 {
+			return fn(objs[0])
+		}
+		return fn(container.NewVBox(objs...))
+	})
+}
+
+// uiSplit returns a container splitting two elements.
+func uiSplit(fn func(a, b fyne.CanvasObject) *container.Split) uiContainer {
+	return func(b *uiBuilder) (fyne.CanvasObject, error) {
+		objs, err := b.children(nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(objs) != 2 {
+			return nil, fmt.Errorf("expected 2 elements, but got %d", len(objs))
+		}
+		return fn(objs[0], objs[1]), nil
+	}
+}
+
+// newForm returns a container laying out labels and widgets in two columns.
+func newForm(objs ...fyne.CanvasObject) *fyne.Container {
+	return container.New(layout.NewFormLayout(), objs...)
+}
+
+func init() {
+	uiContainers = map[string]uiContainer{
+		"vbox":    uiBox(container.NewVBox),
+		"hbox":    uiBox(container.NewHBox),
+		"center":  uiBox(container.NewCenter),
+		"padded":  uiBox(container.NewPadded),
+		"stack":   uiBox(container.NewStack),
+		"form":    uiBox(newForm),
+		"scroll":  uiSingle(container.NewScroll),
+		"vscroll": uiSingle(container.NewVScroll),
+		"hscroll": uiSingle(container.NewHScroll),
+		"hsplit":  uiSplit(container.NewHSplit),
+		"vsplit":  uiSplit(container.NewVSplit),
+		"grid": func(b *uiBuilder) (fyne.CanvasObject, error) {
+			arg, err := b.arg()
+			if err != nil {
+				return nil, err
+			}
+			cols, err := conv_int_fromRye(b.ps, b.ps.Ctx, arg)
+			if err != nil {
+				return nil, err
+			}
+			objs, err := b.children(nil)
+			if err != nil {
+				return nil, err
+			}
+			return container.NewGridWithColumns(cols, objs...), nil
+		},
+		"border": func(b *uiBuilder) (fyne.CanvasObject, error) {
+			blk, err := b.block()
+			if err != nil {
+				return nil, err
+			}
+			els, err := b.build(blk.Series, []string{"top", "bottom", "left", "right", "center"})
+			if err != nil {
+				return nil, err
+			}
+			var top, bottom, left, right fyne.CanvasObject
+			var center []fyne.CanvasObject
+			for _, el := range els {
+				switch el.slot {
+				case "top":
+					top = el.obj
+				case "bottom":
+					bottom = el.obj
+				case "left":
+					left = el.obj
+				case "right":
+					right = el.obj
+				default:
+					center = append(center, el.obj)
+				}
+			}
+			return container.NewBorder(top, bottom, left, right, center...), nil
+		},
+	}
+
+	builtins_fyne["layout"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Builds a user interface described in the layout dialect. More than one element is put into a vbox.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			blk, ok := args[0].(env.Block)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected block, but got " + objectType(ps, args[0]))
+			}
+			b := &uiBuilder{ps: forkProgramState(ps)}
+			els, err := b.build(blk.Series, nil)
+			if err != nil {
+				return b.fail(ps, err)
+			}
+			var obj fyne.CanvasObject
+			if len(els) == 1 {
+				obj = els[0].obj
+			} else {
+				obj = container.NewVBox(uiObjects(els)...)
+			}
+			res, err := conv_fyne_io_fyne_v2_CanvasObject_toRye(ps, ps.Ctx, obj)
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			return res
+		},
+	}
+}
+
+// uiBuilder evaluates a layout block. Arguments are evaluated on ps, whose
+// series is the block being built.
+type uiBuilder struct {
+	ps *env.ProgramState
+}
+
+// errUIEval signals that evaluating Rye code failed, leaving the error in
+// b.ps.Res.
+var errUIEval = errors.New("evaluation failed")
+
+// fail returns the failure for err and sets the flags of ps accordingly.
+func (b *uiBuilder) fail(ps *env.ProgramState, err error) env.Object {
+	ps.FailureFlag = true
+	if err == errUIEval {
+		ps.ErrorFlag = b.ps.ErrorFlag
+		return b.ps.Res
+	}
+	return env.NewError("layout: " + err.Error())
+}
+
+func (b *uiBuilder) atEnd() bool {
+	return b.ps.Ser.Pos() >= b.ps.Ser.Len()
+}
+
+// build builds the elements of ser. Words in slots mark the slot of the
+// element following them.
+func (b *uiBuilder) build(ser env.TSeries, slots []string) ([]uiElement, error) {
+	saved := b.ps.Ser
+	b.ps.Ser = ser
+	b.ps.Ser.Reset()
+	defer func() { b.ps.Ser = saved }()
+
+	var els []uiElement
+	var last env.Object
+	var lastObj fyne.CanvasObject
+	var setword *env.Setword
+	slot := ""
+	for !b.atEnd() {
+		switch x := b.ps.Ser.Peek().(type) {
+		case env.Setword:
+			b.ps.Ser.Next()
+			setword = &x
+			continue
+		case env.LSetword:
+			b.ps.Ser.Next()
+			if last == nil {
+				return nil, errors.New("set-word :" + b.ps.Idx.GetWord(x.Index) + " doesn't follow an element")
+			}
+			if err := b.set(x.Index, last); err != nil {
+				return nil, err
+			}
+			widgetNames.store(lastObj, b.ps.Idx.GetWord(x.Index))
+			continue
+		case env.Pipeword:
+			b.ps.Ser.Next()
+			if last == nil {
+				return nil, errors.New("property |" + b.ps.Idx.GetWord(x.Index) + " doesn't follow an element")
+			}
+			if err := b.property(last, b.ps.Idx.GetWord(x.Index)); err != nil {
+				return nil, err
+			}
+			continue
+		case env.Word:
+			if name := b.ps.Idx.GetWord(x.Index); slot == "" && slices.Contains(slots, name) {
+				b.ps.Ser.Next()
+				slot = name
+				continue
+			}
+		}
+		obj, err := b.element()
+		if err != nil {
+			return nil, err
+		}
+		last, err = conv_fyne_io_fyne_v2_CanvasObject_toRye(b.ps, b.ps.Ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if setword != nil {
+			if err := b.set(setword.Index, last); err != nil {
+				return nil, err
+			}
+			widgetNames.store(obj, b.ps.Idx.GetWord(setword.Index))
+			setword = nil
+		}
+		lastObj = obj
+		els = append(els, uiElement{obj: obj, slot: slot})
+		slot = ""
+	}
+	if setword != nil {
+		return nil, errors.New("set-word " + b.ps.Idx.GetWord(setword.Index) + ": isn't followed by an element")
+	}
+	if slot != "" {
+		return nil, errors.New("slot " + slot + " isn't followed by an element")
+	}
+	return els, nil
+}
+
+// element builds the element at the current position.
+func (b *uiBuilder) element() (fyne.CanvasObject, error) {
+	switch x := b.ps.Ser.Peek().(type) {
+	case env.String:
+		b.ps.Ser.Next()
+		return widget.NewLabel(x.Value), nil
+	case env.Word:
+		name := b.ps.Idx.GetWord(x.Index)
+		if build, ok := uiContainers[name]; ok {
+			b.ps.Ser.Next()
+			obj, err := build(b)
+			if err != nil && err != errUIEval {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			return obj, err
+		}
+		if bi, ok := builtins_fyne_widget[name]; ok {
+			b.ps.Ser.Next()
+			args := make([]env.Object, bi.Argsn)
+			for i := range args {
+				arg, err := b.arg()
+				if err != nil {
+					if err == errUIEval {
+						return nil, err
+					}
+					return nil, fmt.Errorf("%s expects %d arguments: %w", name, bi.Argsn, err)
+				}
+				args[i] = arg
+			}
+			res := bi.Fn(b.ps, args...)
+			if b.ps.FailureFlag || b.ps.ErrorFlag {
+				b.ps.Res = res
+				return nil, errUIEval
+			}
+			obj, err := conv_fyne_io_fyne_v2_CanvasObject_fromRye(b.ps, b.ps.Ctx, res)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			return obj, nil
+		}
+	}
+	evaldo.EvalExpression_CollectArg(b.ps, true)
+	if b.ps.FailureFlag || b.ps.ErrorFlag {
+		return nil, errUIEval
+	}
+	obj, err := conv_fyne_io_fyne_v2_CanvasObject_fromRye(b.ps, b.ps.Ctx, b.ps.Res)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, errors.New("expected element, but got " + objectType(b.ps, b.ps.Res))
+	}
+	return obj, nil
+}
+
+// arg returns the argument at the current position. A curly block becomes a
+// function without arguments.
+func (b *uiBuilder) arg() (env.Object, error) {
+	if b.atEnd() {
+		return nil, errors.New("missing argument")
+	}
+	if blk, ok := b.ps.Ser.Peek().(env.Block); ok && blk.Mode == 0 {
+		b.ps.Ser.Next()
+		return *env.NewFunction(*env.NewBlock(*env.NewTSeries(nil)), blk, false), nil
+	}
+	evaldo.EvalExpression_CollectArg(b.ps, true)
+	if b.ps.FailureFlag || b.ps.ErrorFlag {
+		return nil, errUIEval
+	}
+	return b.ps.Res, nil
+}
+
+// block returns the block at the current position.
+func (b *uiBuilder) block() (env.Block, error) {
+	if !b.atEnd() {
+		if blk, ok := b.ps.Ser.Peek().(env.Block); ok {
+			b.ps.Ser.Next()
+			return blk, nil
+		}
+	}
+	return env.Block{}, errors.New("expected block of elements")
+}
+
+// children builds the block of elements at the current position.
+func (b *uiBuilder) children(slots []string) ([]fyne.CanvasObject, error) {
+	blk, err := b.block()
+	if err != nil {
+		return nil, err
+	}
+	els, err := b.build(blk.Series, slots)
+	if err != nil {
+		return nil, err
+	}
+	return uiObjects(els), nil
+}
+
+// property calls the setter or method name on obj with the arguments that
+// follow.
+func (b *uiBuilder) property(obj env.Object, name string) error {
+	nat, ok := obj.(env.Native)
+	if !ok {
+		return errors.New("can't set |" + name + " on " + objectType(b.ps, obj))
+	}
+	var bi env.VarBuiltin
+	found := false
+	for _, method := range []string{name + "!", "set-" + name, name} {
+		if m, ok := b.ps.Gen.Get(nat.Kind.Index, b.ps.Idx.IndexWord(method)); ok {
+			if bi, ok = m.(env.VarBuiltin); ok {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return errors.New(b.ps.Idx.GetWord(nat.Kind.Index) + " has no property |" + name)
+	}
+	args := []env.Object{obj}
+	for i := 1; i < bi.Argsn; i++ {
+		arg, err := b.arg()
+		if err != nil {
+			if err == errUIEval {
+				return err
+			}
+			return fmt.Errorf("|%s expects %d arguments: %w", name, bi.Argsn-1, err)
+		}
+		args = append(args, arg)
+	}
+	res := bi.Fn(b.ps, args...)
+	if b.ps.FailureFlag || b.ps.ErrorFlag {
+		b.ps.Res = res
+		return errUIEval
+	}
+	return nil
+}
+
+// set sets the word idx to val, like a set-word does.
+func (b *uiBuilder) set(idx int, val env.Object) error {
+	if b.ps.AllowMod {
+		if !b.ps.Ctx.Mod(idx, val) {
+			return errors.New("cannot modify constant " + b.ps.Idx.GetWord(idx))
+		}
+	} else if !b.ps.Ctx.SetNew(idx, val, b.ps.Idx) {
+		return errors.New("cannot set word " + b.ps.Idx.GetWord(idx) + " because it's already set")
+	}
+	return nil
+}
+
+func uiObjects(els []uiElement) []fyne.CanvasObject {
+	objs := make([]fyne.CanvasObject, len(els))
+	for i, el := range els {
+		objs[i] = el.obj
+	}
+	return objs
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestLayoutDialect(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		ui: fyne/layout {
+			form {
+				"Name:" name: entry
+				"Mood:" select [ "Happy" "Sad" ] fn { x } { } |place-holder "Pick one" :mood
+			}
+			hbox { button "Save" { } |importance 'high  button "Cancel" { } :cancel }
+		}
+	`)
+	ui := testValue[*fyne.Container](t, ps, "ui")
+	if len(ui.Objects) != 2 {
+		t.Fatalf("got %d elements, want a vbox of 2", len(ui.Objects))
+	}
+	form, buttons := ui.Objects[0].(*fyne.Container), ui.Objects[1].(*fyne.Container)
+	if len(form.Objects) != 4 || len(buttons.Objects) != 2 {
+		t.Fatalf("got %d objects in the form and %d buttons, want 4 and 2", len(form.Objects), len(buttons.Objects))
+	}
+	if l, ok := form.Objects[0].(*widget.Label); !ok || l.Text != "Name:" {
+		t.Errorf("got %#v, want the label \"Name:\"", form.Objects[0])
+	}
+
+	name := testValue[*widget.Entry](t, ps, "name")
+	mood := testValue[*widget.Select](t, ps, "mood")
+	cancel := testValue[*widget.Button](t, ps, "cancel")
+	if form.Objects[1] != name || form.Objects[3] != mood || buttons.Objects[1] != cancel {
+		t.Error("the set words don't hold the elements of the tree")
+	}
+	if mood.PlaceHolder != "Pick one" {
+		t.Errorf("got place holder %q, want \"Pick one\"", mood.PlaceHolder)
+	}
+	if save := buttons.Objects[0].(*widget.Button); save.Importance != widget.HighImportance {
+		t.Errorf("got importance %v, want high", save.Importance)
+	}
+	for obj, want := range map[fyne.CanvasObject]string{name: "name", mood: "mood", cancel: "cancel"} {
+		if got, ok := widgetNames.load(obj); !ok || got != want {
+			t.Errorf("got name %q, want %q", got, want)
+		}
+	}
+	if _, ok := widgetNames.load(buttons.Objects[0]); ok {
+		t.Error("the unnamed button got a name")
+	}
+}
+
+func TestLayoutBorder(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		ui: fyne/layout {
+			border { top "top" left "left" "a" "b" bottom "bottom" }
+		}
+	`)
+	ui := testValue[*fyne.Container](t, ps, "ui")
+	texts := func(objs []fyne.CanvasObject) (s []string) {
+		for _, o := range objs {
+			s = append(s, o.(*widget.Label).Text)
+		}
+		return s
+	}
+	// container.NewBorder puts the center objects first.
+	want := texts(container.NewBorder(widget.NewLabel("top"), widget.NewLabel("bottom"), widget.NewLabel("left"), nil, widget.NewLabel("a"), widget.NewLabel("b")).Objects)
+	if got := texts(ui.Objects); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"unknown container", `fyne/layout { boxes { "a" } }`, "Word not found: `boxes`"},
+		{"bad slot", `fyne/layout { border { middle "a" } }`, "Word not found: `middle`"},
+		{"slot without element", `fyne/layout { border { "a" top } }`, "layout: border: slot top isn't followed by an element"},
+		{"set-word without element", `fyne/layout { "a" x: }`, "layout: set-word x: isn't followed by an element"},
+		{"property on a container", `fyne/layout { vbox { } |importance 'high }`, "layout: go(*fyne/Container) has no property |importance"},
+		{"property without element", `fyne/layout { |importance 'high }`, "layout: property |importance doesn't follow an element"},
+		{"not an element", `fyne/layout { 1 }`, "layout: expected native interface or context with methods"},
+		{"missing argument", `fyne/layout { button "Save" }`, "layout: button expects 2 arguments: missing argument"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := evalTest(t, testProgramState(t), `import\go\all`)
+			ps = evalTest(t, ps, `err: try { `+tt.src+` }`)
+			obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("err"))
+			e, ok := obj.(*env.Error)
+			if !ok {
+				t.Fatalf("got %s, want an error", objectType(ps, obj))
+			}
+			if !strings.Contains(e.Message, tt.want) {
+				t.Errorf("got error %q, want %q", e.Message, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"weak"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// listView shows the items of a Rye block or list in a widget.List and keeps
+// track of the selected item.
+type listView struct {
+	ps       *env.ProgramState
+	list     *widget.List
+	template *env.Function // nil to show items as labels
+	rendered map[*fyne.Container]listRow
+
+	// mu guards the fields below, which the script reads and sets while
+	// fyne reads them on the main goroutine.
+	mu           sync.Mutex
+	items        []env.Object
+	selected     int // -1 if nothing is selected
+	onSelected   *env.Function
+	onUnselected *env.Function
+}
+
+// listRow is the item a row was last rendered for.
+type listRow struct {
+	id   widget.ListItemID
+	item env.Object
+}
+
+// listViews maps lists created by list-from to their views, for the
+// selection methods. The views are held weakly too, as they reference their
+// lists.
+var listViews objectMap[weak.Pointer[listView]]
+
+func newListView(ps *env.ProgramState, items []env.Object, template *env.Function) *listView {
+	v := &listView{ps: ps, items: items, template: template, selected: -1}
+	v.rendered = map[*fyne.Container]listRow{}
+	v.list = widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.items)
+		},
+		func() fyne.CanvasObject {
+			if v.template != nil {
+				return container.NewStack()
+			}
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			item, ok := v.item(id)
+			if !ok {
+				return
+			}
+			if v.template == nil {
+				o.(*widget.Label).SetText(item.Print(*v.ps.Idx))
+				return
+			}
+			// fyne refreshes all visible rows when one of them changes, so
+			// the template is only called for rows showing another item.
+			stack := o.(*fyne.Container)
+			if row, ok := v.rendered[stack]; ok && row.id == id && row.item.Equal(item) {
+				return
+			}
+			ps := forkProgramState(v.ps)
+			evaldo.CallFunctionArgsN(*v.template, ps, nil, item)
+			if e, ok := ps.Res.(*env.Error); ok {
+				showFunctionError(ps, *v.template, errors.New(e.Message))
+				return
+			}
+			obj, err := conv_fyne_io_fyne_v2_CanvasObject_fromRye(ps, nil, ps.Res)
+			if err != nil {
+				showFunctionError(ps, *v.template, err)
+				return
+			}
+			stack.Objects = []fyne.CanvasObject{obj}
+			stack.Refresh()
+			v.rendered[stack] = listRow{id, item}
+		},
+	)
+	v.list.OnSelected = func(id widget.ListItemID) {
+		v.mu.Lock()
+		v.selected = id
+		fn := v.onSelected
+		v.mu.Unlock()
+		v.call(fn, id)
+	}
+	v.list.OnUnselected = func(id widget.ListItemID) {
+		v.mu.Lock()
+		if v.selected == id {
+			v.selected = -1
+		}
+		fn := v.onUnselected
+		v.mu.Unlock()
+		v.call(fn, id)
+	}
+	listViews.store(v.list, weak.Make(v))
+	return v
+}
+
+// item returns the item of row id.
+func (v *listView) item(id widget.ListItemID) (env.Object, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if id < 0 || id >= len(v.items) {
+		return nil, false
+	}
+	return v.items[id], true
+}
+
+// call calls fn, if set, with the item of row id and its index.
+func (v *listView) call(fn *env.Function, id widget.ListItemID) {
+	item, ok := v.item(id)
+	if fn == nil || !ok {
+		return
+	}
+	ps := forkProgramState(v.ps)
+	evaldo.CallFunctionArgsN(*fn, ps, nil, item, *env.NewInteger(int64(id)))
+	if e, ok := ps.Res.(*env.Error); ok {
+		showFunctionError(ps, *fn, errors.New(e.Message))
+	}
+}
+
+// setItems replaces the shown items. If only some items changed, only their
+// rows are refreshed.
+func (v *listView) setItems(items []env.Object) {
+	v.mu.Lock()
+	old := v.items
+	v.items = items
+	unselect := v.selected >= len(items)
+	v.mu.Unlock()
+	if unselect {
+		v.list.UnselectAll()
+	}
+	if len(old) != len(items) {
+		v.list.Refresh()
+		return
+	}
+	for i := range items {
+		if !items[i].Equal(old[i]) {
+			v.list.RefreshItem(i)
+		}
+	}
+}
+
+func listItemsFromRye(obj env.Object) ([]env.Object, bool) {
+	switch x := obj.(type) {
+	case env.Block:
+		return append([]env.Object(nil), x.Series.GetAll()...), true
+	case env.List:
+		items := make([]env.Object, len(x.Data))
+		for i, v := range x.Data {
+			items[i] = env.ToRyeValue(v)
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// listFrom creates a list view of a block or list, or of the variable
+// named by a word holding one.
+func listFrom(ps *env.ProgramState, arg env.Object, template *env.Function) env.Object {
+	if items, ok := listItemsFromRye(arg); ok {
+		nat, _ := autoToNative(ps, newListView(ps, items, template).list)
+		return nat
+	}
+	word, ok := arg.(env.Word)
+	if !ok {
+		ps.FailureFlag = true
+		return env.NewError("expected block, list or word naming a variable, but got " + objectType(ps, arg))
+	}
+	ctx, err := findVar(ps, word)
+	if err != nil {
+		ps.FailureFlag = true
+		return env.NewError(err.Error())
+	}
+	name := ps.Idx.GetWord(word.Index)
+	cur, _ := ctx.GetCurrent(word.Index)
+	items, ok := listItemsFromRye(cur)
+	if !ok {
+		ps.FailureFlag = true
+		return env.NewError("expected variable '" + name + "' to hold a block or list, but got " + objectType(ps, cur))
+	}
+	v := newListView(ps, items, template)
+	// The observer holds the view weakly, so a list that is dropped is
+	// collected, and the function removed from the observer.
+	wv := weak.Make(v)
+	remove := observeVar(ctx, word.Index, func(ps *env.ProgramState, val env.Object) {
+		v := wv.Value()
+		if v == nil {
+			return
+		}
+		items, ok := listItemsFromRye(val)
+		if !ok {
+			writeBindingError(name, errors.New("expected block or list, but got "+objectType(ps, val)))
+			return
+		}
+		fyne.Do(func() { v.setItems(items) })
+	})
+	runtime.AddCleanup(v, func(remove func()) { remove() }, remove)
+	nat, _ := autoToNative(ps, v.list)
+	return nat
+}
+
+// listViewFromRye returns the view of a list created by list-from.
+func listViewFromRye(ps *env.ProgramState, obj env.Object) (*listView, error) {
+	if nat, ok := obj.(env.Native); ok {
+		if l, ok := nat.Value.(*widget.List); ok {
+			if v, ok := listViews.load(l); ok {
+				return v.Value(), nil
+			}
+			return nil, errors.New("expected list created by list-from")
+		}
+	}
+	return nil, errors.New("expected native of type go(*widget/List), but got " + objectType(ps, obj))
+}
+
+func selectionCallback(ps *env.ProgramState, obj env.Object) (*env.Function, error) {
+	if isNil(obj) {
+		return nil, nil
+	}
+	fn, ok := obj.(env.Function)
+	if !ok || fn.Argsn > 2 {
+		return nil, errors.New("expected function with up to 2 args, but got " + objectType(ps, obj))
+	}
+	return &fn, nil
+}
+
+func init() {
+	m := builtins_fyne_widget
+	m["list-from"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a list widget showing the items of a block or list, or of the variable named by the word, as labels.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return listFrom(ps, args[0], nil)
+		},
+	}
+	m["list-from\\template"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Creates a list widget showing the items of a block or list, or of the variable named by the word, with the widgets returned by the template function for each item.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			fn, ok := args[1].(env.Function)
+			if !ok || fn.Argsn != 1 {
+				ps.FailureFlag = true
+				return env.NewError("expected function with 1 arg, but got " + objectType(ps, args[1]))
+			}
+			return listFrom(ps, args[0], &fn)
+		},
+	}
+	m["go(*widget/List)//selected-item?"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the selected item of a list created by list-from, or void if there is none.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			v, err := listViewFromRye(ps, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			v.mu.Lock()
+			selected := v.selected
+			v.mu.Unlock()
+			if item, ok := v.item(selected); ok {
+				return item
+			}
+			return *env.NewVoid()
+		},
+	}
+	m["go(*widget/List)//on-item-selected!"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Sets the function called with the item and its index when an item of a list created by list-from is selected.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			v, err := listViewFromRye(ps, args[0])
+			var fn *env.Function
+			if err == nil {
+				fn, err = selectionCallback(ps, args[1])
+			}
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			v.mu.Lock()
+			v.onSelected = fn
+			v.mu.Unlock()
+			return args[0]
+		},
+	}
+	m["go(*widget/List)//on-item-unselected!"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Sets the function called with the item and its index when an item of a list created by list-from is unselected.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			v, err := listViewFromRye(ps, args[0])
+			var fn *env.Function
+			if err == nil {
+				fn, err = selectionCallback(ps, args[1])
+			}
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			v.mu.Lock()
+			v.onUnselected = fn
+			v.mu.Unlock()
+			return args[0]
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestListFrom(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		l: widget/list-from { "a" "b" "c" }
+	`)
+	l := testValue[*widget.List](t, ps, "l")
+	if n := l.Length(); n != 3 {
+		t.Fatalf("got %d items, want 3", n)
+	}
+	w := test.NewWindow(l)
+	defer w.Close()
+	o := l.CreateItem()
+	l.UpdateItem(1, o)
+	if label, ok := o.(*widget.Label); !ok || label.Text != "b" {
+		t.Errorf("got item %#v, want a label with \"b\"", o)
+	}
+
+	ps = evalTest(t, ps, `tl: widget/list-from\template { "x" } fn { s } { widget/entry }`)
+	tl := testValue[*widget.List](t, ps, "tl")
+	o = tl.CreateItem()
+	tl.UpdateItem(0, o)
+	if len(test.LaidOutObjects(o)) == 0 {
+		t.Errorf("got no template widget in %#v", o)
+	}
+	if _, err := evalSource(ps, "test.rye", `widget/list-from 1`); err == nil {
+		t.Error("got no error for an integer")
+	}
+}
+
+func TestListSelection(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		picked: channel 1
+		l: widget/list-from { "a" "b" }
+		l .on-item-selected! fn { item i } { picked .Send item }
+		none: l .selected-item?
+	`)
+	if obj, _ := ps.Ctx.Get(ps.Idx.IndexWord("none")); obj.Type() != env.VoidType {
+		t.Errorf("got %s selected before selecting, want void", objectType(ps, obj))
+	}
+	testValue[*widget.List](t, ps, "l").Select(1)
+	if obj := *<-testValue[chan *env.Object](t, ps, "picked"); !isString(obj, "b") {
+		t.Errorf("got %s passed to on-item-selected!, want \"b\"", objectType(ps, obj))
+	}
+	if obj := evalTest(t, ps, `l .selected-item?`).Res; !isString(obj, "b") {
+		t.Errorf("got %s selected, want \"b\"", objectType(ps, obj))
+	}
+}
+
+func isString(obj env.Object, want string) bool {
+	s, ok := obj.(env.String)
+	return ok && s.Value == want
+}
+
+func TestListRefresh(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		var 'items { "a" }
+		l: widget/list-from 'items
+		l .on-item-selected! fn { item i } { }
+	`)
+	l := testValue[*widget.List](t, ps, "l")
+	l.Select(0)
+	evalTest(t, ps, `change! { "x" "y" "z" } 'items`)
+	waitFor(t, "the new items", func() bool { return l.Length() == 3 })
+
+	evalTest(t, ps, `change! { } 'items`)
+	waitFor(t, "the items to be removed", func() bool { return l.Length() == 0 })
+	if obj := evalTest(t, ps, `l .selected-item?`).Res; obj.Type() != env.VoidType {
+		t.Errorf("got %s selected after removing the items, want void", objectType(ps, obj))
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"github.com/BurntSushi/toml"
+)
+
+// A FyneApp.toml in the directory of the script sets the metadata of the app
+// before the script runs, like the fyne tool does for Go apps, so app/new
+// gets the ID the preferences and storage are kept under, and app .metadata
+// and notifications get the name, version and icon. The custom metadata is
+// that of the [Development] table, or of [Release] in apps built with
+// `rye-fyne build -release`. The build command uses the manifest for the
+// metadata not given with flags.
+
+// manifestFile is the name of the app manifest.
+const manifestFile = "FyneApp.toml"
+
+// appManifest is the part of FyneApp.toml rye-fyne uses.
+type appManifest struct {
+	Details struct {
+		Icon    string
+		Name    string
+		ID      string
+		Version string
+		Build   int
+	}
+	Development map[string]string
+	Release     map[string]string
+	Migrations  map[string]bool
+}
+
+// loadManifest reads the manifest in dir. It returns nil if there is none.
+func loadManifest(dir string) (*appManifest, error) {
+	var m appManifest
+	_, err := toml.DecodeFile(filepath.Join(dir, manifestFile), &m)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, manifestFile), err)
+	}
+	return &m, nil
+}
+
+// custom returns the custom metadata for release or development builds.
+func (m *appManifest) custom(release bool) map[string]string {
+	if release {
+		return m.Release
+	}
+	return m.Development
+}
+
+// applyManifest sets the metadata of the app from the manifest in dir, if
+// there is one.
+func applyManifest(dir string) error {
+	meta, err := manifestMetadata(dir)
+	if meta == nil {
+		return err
+	}
+	app.SetMetadata(*meta)
+	return nil
+}
+
+// manifestMetadata returns the metadata of the app from the manifest in
+// dir, or nil if there is none.
+func manifestMetadata(dir string) (*fyne.AppMetadata, error) {
+	m, err := loadManifest(dir)
+	if m == nil {
+		return nil, err
+	}
+	meta := &fyne.AppMetadata{
+		ID:         m.Details.ID,
+		Name:       m.Details.Name,
+		Version:    m.Details.Version,
+		Build:      m.Details.Build,
+		Custom:     m.custom(false),
+		Migrations: m.Migrations,
+	}
+	if meta.Version == "" {
+		meta.Version = "0.0.1"
+	}
+	if meta.Build == 0 {
+		meta.Build = 1
+	}
+	if m.Details.Icon != "" {
+		icon, err := fyne.LoadResourceFromPath(filepath.Join(dir, m.Details.Icon))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, manifestFile), err)
+		}
+		meta.Icon = icon
+	}
+	return meta, nil
+}
+
+// scriptDir returns the directory of the script at path, which is the
+// directory of main.rye if path is a directory.
+func scriptDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
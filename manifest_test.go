@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManifestMetadata(t *testing.T) {
+	meta, err := manifestMetadata("examples/hello-world-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ID != "com.refaktorlabs.ryefynehello" || meta.Name != "hello-world-build" {
+		t.Errorf("got ID %q and name %q", meta.ID, meta.Name)
+	}
+	if meta.Icon == nil || meta.Icon.Name() != "Icon.png" || len(meta.Icon.Content()) == 0 {
+		t.Errorf("got icon %v, want Icon.png", meta.Icon)
+	}
+
+	dir := t.TempDir()
+	write := func(manifest string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, manifestFile), []byte(manifest), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+		[Details]
+		ID = "com.example.app"
+		[Development]
+		server = "localhost"
+		[Release]
+		server = "example.com"
+	`)
+	meta, err = manifestMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Version != "0.0.1" || meta.Build != 1 || meta.Icon != nil {
+		t.Errorf("got version %q, build %d and icon %v, want the defaults", meta.Version, meta.Build, meta.Icon)
+	}
+	if meta.Custom["server"] != "localhost" {
+		t.Errorf("got custom metadata %v, want that of development", meta.Custom)
+	}
+
+	write("[Details]\nIcon = \"missing.png\"\n")
+	if _, err := manifestMetadata(dir); err == nil || !strings.Contains(err.Error(), manifestFile) {
+		t.Errorf("got error %v for a missing icon", err)
+	}
+	write("[Details\n")
+	if _, err := manifestMetadata(dir); err == nil || !strings.Contains(err.Error(), manifestFile) {
+		t.Errorf("got error %v for a broken manifest", err)
+	}
+}
+
+func TestManifestMissing(t *testing.T) {
+	dir := t.TempDir()
+	if meta, err := manifestMetadata(dir); meta != nil || err != nil {
+		t.Errorf("got %v, %v without a manifest, want nil", meta, err)
+	}
+	if err := applyManifest(dir); err != nil {
+		t.Errorf("got error %v without a manifest", err)
+	}
+}
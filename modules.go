@@ -0,0 +1,158 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+	"github.com/refaktor/rye/loader"
+)
+
+// Bigger apps are split into modules, like ui/forms.rye and state/todos.rye,
+// loaded with require. A module is a Rye file evaluated in its own context,
+// which sees the builtins but not the words of the script requiring it, and
+// require returns that context:
+//
+//	forms: require %ui/forms.rye
+//	forms/todo-form todos
+//
+// Paths are relative to the directory of the main script, or the embedded
+// directory in apps made by `rye-fyne build`, wherever the module requiring
+// them is. Each module is evaluated once per program, later requires return
+// the same context, and a module requiring itself through others is an
+// error.
+
+// moduleDir is the directory modules are resolved in, that of the main
+// script.
+var moduleDir = "."
+
+// moduleSet are the modules of a program.
+type moduleSet struct {
+	loaded map[string]*env.RyeCtx
+	// loading are the modules being evaluated by each program state, each
+	// requiring the next. Goroutines and callbacks evaluate in program
+	// states of their own, so one doesn't see the others' modules as cycles.
+	loading map[*env.ProgramState][]string
+}
+
+// programModules are the modules by the root context of their program, so
+// that a program evaluated again, like with -watch, loads them again.
+// modulesMu guards them and the module sets, as require can be called from
+// goroutines and callbacks. It isn't held while a module is evaluated, so a
+// module requiring another doesn't wait for itself.
+var (
+	modulesMu      sync.Mutex
+	programModules = map[*env.RyeCtx]*moduleSet{}
+)
+
+func init() {
+	baseBuiltins["require"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Evaluates a Rye file, relative to the main script, in its own context and returns the context. A module is evaluated only once.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			u, ok := args[0].(env.Uri)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected uri, but got " + objectType(ps, args[0]))
+			}
+			return requireModule(ps, u.GetPath())
+		},
+	}
+}
+
+// requireModule returns the context of the module at path, evaluating it
+// if it isn't loaded yet.
+func requireModule(ps *env.ProgramState, path string) env.Object {
+	root := rootContext(ps.Ctx)
+	file := path
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(moduleDir, file)
+	}
+	modulesMu.Lock()
+	mods, ok := programModules[root]
+	if !ok {
+		mods = &moduleSet{loaded: map[string]*env.RyeCtx{}, loading: map[*env.ProgramState][]string{}}
+		programModules[root] = mods
+	}
+	if ctx, ok := mods.loaded[file]; ok {
+		modulesMu.Unlock()
+		return *ctx
+	}
+	loading := mods.loading[ps]
+	if slices.Contains(loading, file) {
+		cycle := append(slices.Clone(loading), file)
+		modulesMu.Unlock()
+		i := slices.Index(cycle, file)
+		ps.FailureFlag = true
+		return env.NewError("import cycle: " + strings.Join(cycle[i:], " -> "))
+	}
+	mods.loading[ps] = append(loading, file)
+	modulesMu.Unlock()
+	defer func() {
+		modulesMu.Lock()
+		if len(loading) == 0 {
+			delete(mods.loading, ps)
+		} else {
+			mods.loading[ps] = loading
+		}
+		modulesMu.Unlock()
+	}()
+
+	source, err := readAppFile(file)
+	if err != nil {
+		ps.FailureFlag = true
+		return env.NewError(err.Error())
+	}
+	script := ps.ScriptPath
+	ps.ScriptPath = file
+	defer func() {
+		ps.ScriptPath = script
+	}()
+	switch val := loader.LoadStringNEW(" "+string(source)+"\n", false, ps).(type) {
+	case env.Block:
+		ser, ctx := ps.Ser, ps.Ctx
+		ps.Ser = val.Series
+		ps.Ctx = env.NewEnv(root)
+		evaldo.EvalBlock(ps)
+		mod := ps.Ctx
+		ps.Ser, ps.Ctx = ser, ctx
+		if ps.ErrorFlag || ps.FailureFlag {
+			return ps.Res
+		}
+		modulesMu.Lock()
+		defer modulesMu.Unlock()
+		// Of a module loaded by two goroutines at once, the first one
+		// loaded is kept.
+		if loaded, ok := mods.loaded[file]; ok {
+			return *loaded
+		}
+		mods.loaded[file] = mod
+		return *mod
+	case env.Error:
+		ps.FailureFlag = true
+		return env.NewError(file + ": " + ansiEscapes.ReplaceAllString(val.Message, ""))
+	}
+	ps.FailureFlag = true
+	return env.NewError("can't load " + file)
+}
+
+// forgetModules drops the modules of the program of ps, which is not used
+// anymore.
+func forgetModules(ps *env.ProgramState) {
+	if ps != nil {
+		modulesMu.Lock()
+		delete(programModules, rootContext(ps.Ctx))
+		modulesMu.Unlock()
+	}
+}
+
+// rootContext returns the context of the builtins ctx is in.
+func rootContext(ctx *env.RyeCtx) *env.RyeCtx {
+	for ctx.Parent != nil {
+		ctx = ctx.Parent
+	}
+	return ctx
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/refaktor/rye/env"
+)
+
+// Goroutines of a script can require modules at once. Run with -race.
+func TestRequireFromGoroutines(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.rye"), []byte(`b: require %b.rye x: b/y + 1`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.rye"), []byte(`y: 1`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := moduleDir
+	moduleDir = dir
+	defer func() { moduleDir = old }()
+
+	ps := testProgramState(t)
+	defer forgetModules(ps)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			ps := forkProgramState(ps)
+			res := requireModule(ps, "a.rye")
+			ctx, ok := res.(env.RyeCtx)
+			if !ok {
+				t.Errorf("got %s", objectType(ps, res))
+				return
+			}
+			if x, _ := ctx.Get(ps.Idx.IndexWord("x")); x == nil || !x.Equal(*env.NewInteger(2)) {
+				t.Errorf("got x %v, want 2", x)
+			}
+		})
+	}
+	wg.Wait()
+}
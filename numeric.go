@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/refaktor/rye/env"
+)
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+type float interface {
+	~float32 | ~float64
+}
+
+// intFromRye converts a Rye integer or a whole decimal to T. ok is false if
+// obj is neither, err is set if the value doesn't fit into T.
+func intFromRye[T integer](obj env.Object, typeName string) (_ T, ok bool, err error) {
+	var v int64
+	switch x := obj.(type) {
+	case env.Integer:
+		v = x.Value
+	case env.Decimal:
+		if x.Value != math.Trunc(x.Value) {
+			return 0, true, fmt.Errorf("expected %s, but got decimal %s with a fractional part", typeName, formatDecimal(x.Value))
+		}
+		if x.Value < math.MinInt64 || x.Value >= math.MaxInt64 {
+			return 0, true, fmt.Errorf("decimal %s overflows %s", formatDecimal(x.Value), typeName)
+		}
+		v = int64(x.Value)
+	default:
+		return 0, false, nil
+	}
+	r := T(v)
+	if int64(r) != v || (v < 0) != (r < 0) {
+		return 0, true, fmt.Errorf("integer %d overflows %s", v, typeName)
+	}
+	return r, true, nil
+}
+
+// floatFromRye converts a Rye decimal or integer to T. ok is false if obj is
+// neither, err is set if the value can't be represented by T.
+func floatFromRye[T float](obj env.Object, typeName string) (_ T, ok bool, err error) {
+	switch x := obj.(type) {
+	case env.Decimal:
+		r := T(x.Value)
+		if math.IsInf(float64(r), 0) && !math.IsInf(x.Value, 0) {
+			return 0, true, fmt.Errorf("decimal %s overflows %s", formatDecimal(x.Value), typeName)
+		}
+		return r, true, nil
+	case env.Integer:
+		r := T(x.Value)
+		if float64(r) >= math.MaxInt64 || int64(r) != x.Value {
+			return 0, true, fmt.Errorf("integer %d can't be represented exactly as %s", x.Value, typeName)
+		}
+		return r, true, nil
+	}
+	return 0, false, nil
+}
+
+func formatDecimal(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
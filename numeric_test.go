@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/refaktor/rye/env"
+)
+
+func TestIntFromRye(t *testing.T) {
+	tests := []struct {
+		name  string
+		obj   env.Object
+		conv  func(env.Object) (any, bool, error)
+		want  any
+		fails bool
+	}{
+		{"int8 max", *env.NewInteger(127), int8FromRye, int8(127), false},
+		{"int8 min", *env.NewInteger(-128), int8FromRye, int8(-128), false},
+		{"int8 over", *env.NewInteger(128), int8FromRye, nil, true},
+		{"int8 under", *env.NewInteger(-129), int8FromRye, nil, true},
+		{"int8 whole decimal", *env.NewDecimal(-3), int8FromRye, int8(-3), false},
+		{"int8 fractional", *env.NewDecimal(1.5), int8FromRye, nil, true},
+		{"uint", *env.NewInteger(math.MaxInt64), uintFromRye, uint(math.MaxInt64), false},
+		{"uint negative", *env.NewInteger(-1), uintFromRye, nil, true},
+		{"uint negative decimal", *env.NewDecimal(-1), uintFromRye, nil, true},
+		{"uint fractional", *env.NewDecimal(0.5), uintFromRye, nil, true},
+		{"uint decimal over", *env.NewDecimal(1e20), uintFromRye, nil, true},
+		{"uint8 over", *env.NewInteger(256), uint8FromRye, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.conv(tt.obj)
+			if !ok {
+				t.Fatal("not converted")
+			}
+			if tt.fails {
+				if err == nil {
+					t.Errorf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+	if _, ok, _ := intFromRye[int](*env.NewString("1"), "int"); ok {
+		t.Error("a string was converted")
+	}
+}
+
+func TestFloatFromRye(t *testing.T) {
+	tests := []struct {
+		name  string
+		obj   env.Object
+		conv  func(env.Object) (any, bool, error)
+		want  any
+		fails bool
+	}{
+		{"float32 decimal", *env.NewDecimal(1.5), float32FromRye, float32(1.5), false},
+		{"float32 integer", *env.NewInteger(1 << 24), float32FromRye, float32(1 << 24), false},
+		{"float32 inexact integer", *env.NewInteger(1<<24 + 1), float32FromRye, nil, true},
+		{"float32 overflow", *env.NewDecimal(1e39), float32FromRye, nil, true},
+		{"float32 infinity", *env.NewDecimal(math.Inf(1)), float32FromRye, float32(math.Inf(1)), false},
+		{"float64 integer", *env.NewInteger(-7), float64FromRye, float64(-7), false},
+		{"float64 inexact integer", *env.NewInteger(1<<53 + 1), float64FromRye, nil, true},
+		{"float64 max integer", *env.NewInteger(math.MaxInt64), float64FromRye, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.conv(tt.obj)
+			if !ok {
+				t.Fatal("not converted")
+			}
+			if tt.fails {
+				if err == nil {
+					t.Errorf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func int8FromRye(obj env.Object) (any, bool, error)  { return intFromRye[int8](obj, "int8") }
+func uint8FromRye(obj env.Object) (any, bool, error) { return intFromRye[uint8](obj, "uint8") }
+func uintFromRye(obj env.Object) (any, bool, error)  { return intFromRye[uint](obj, "uint") }
+
+func float32FromRye(obj env.Object) (any, bool, error) {
+	return floatFromRye[float32](obj, "float32")
+}
+
+func float64FromRye(obj env.Object) (any, bool, error) {
+	return floatFromRye[float64](obj, "float64")
+}
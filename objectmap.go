@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// objectMap maps objects, like the widgets made by list-from, to values
+// without keeping the objects alive. The entry of an object is deleted when
+// the object is garbage collected, so a value must not reference its object,
+// or the object never is.
+type objectMap[V any] struct {
+	m sync.Map // weak pointer of the object to V
+}
+
+// objectKey returns the weak pointer of o. ok is false if o isn't a pointer.
+func objectKey(o any) (key weak.Pointer[byte], p *byte, ok bool) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return key, nil, false
+	}
+	p = (*byte)(v.UnsafePointer())
+	return weak.Make(p), p, true
+}
+
+func (m *objectMap[V]) store(o any, v V) {
+	key, p, ok := objectKey(o)
+	if !ok {
+		return
+	}
+	if _, loaded := m.m.Swap(key, v); !loaded {
+		runtime.AddCleanup(p, func(key weak.Pointer[byte]) { m.m.Delete(key) }, key)
+	}
+}
+
+func (m *objectMap[V]) load(o any) (v V, ok bool) {
+	key, _, ok := objectKey(o)
+	if !ok {
+		return v, false
+	}
+	x, ok := m.m.Load(key)
+	if !ok {
+		return v, false
+	}
+	return x.(V), true
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestObjectMapDeletesCollected(t *testing.T) {
+	var m objectMap[string]
+	kept := new([16]int)
+	m.store(kept, "kept")
+	m.store(new([16]int), "collected")
+
+	for i := 0; ; i++ {
+		runtime.GC()
+		n := 0
+		m.m.Range(func(any, any) bool { n++; return true })
+		if n == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("got %d entries after collection, want 1", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v, ok := m.load(kept); !ok || v != "kept" {
+		t.Errorf("got %q, %v for the kept object", v, ok)
+	}
+}
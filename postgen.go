@@ -0,0 +1,706 @@
+//go:build ignore
+
+// Postgen makes the changes to the files ryegen writes that its templates
+// have no options for, and writes enums.gen.go, the enum types of the bound
+// packages. It runs after ryegen and before gendocs:
+//
+//	go run postgen.go
+//
+// Each change fails if it finds nothing to change, so a ryegen update that
+// changes the code it matches is noticed instead of the change being lost.
+// Files changed already are left as they are.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+)
+
+const enumsOutput = "enums.gen.go"
+
+var (
+	fset = token.NewFileSet()
+
+	// headerRe is the header of a file ryegen wrote, with the command.
+	headerRe = regexp.MustCompile(`^// Code generated by (ryegen[^;]*?)( and postgen\.go)?; DO NOT EDIT\.\n`)
+)
+
+// genFile is a file generated by ryegen.
+type genFile struct {
+	name string
+	src  string
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("postgen: ")
+	convs := globGen("ryegen_convs_*.gen.go")
+	builtins := globGen("ryegen_builtins_*.gen.go")
+
+	enums, err := loadEnums()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeEnums(enums); err != nil {
+		log.Fatal(err)
+	}
+
+	// The Rye names of the packages by their aliases in any of the files, as
+	// the kinds of natives name types of packages a file may not import.
+	names := map[string]string{}
+	for _, name := range append(convs, builtins...) {
+		for alias, p := range fileImports(name) {
+			names[alias] = packageName(p)
+		}
+	}
+
+	for _, name := range convs {
+		f, ok := readGenFile(name)
+		if !ok {
+			continue
+		}
+		forkCallbacks(f)
+		goErrors(f)
+		numbers(f)
+		enumWords(f, enums)
+		callbackErrors(f)
+		nativeNames(f, names)
+		argErrors(f)
+		f.write()
+	}
+	for _, name := range builtins {
+		f, ok := readGenFile(name)
+		if !ok {
+			continue
+		}
+		registerBuiltins(f)
+		commands(f)
+		lazyPackages(f)
+		nativeNames(f, names)
+		f.write()
+	}
+}
+
+func globGen(pattern string) []string {
+	files, err := filepath.Glob(pattern)
+	if err != nil || len(files) == 0 {
+		log.Fatalf("no %s, run ryegen first", pattern)
+	}
+	return files
+}
+
+// readGenFile reads a file generated by ryegen. ok is false if postgen
+// changed it already.
+func readGenFile(name string) (_ *genFile, ok bool) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src := string(b)
+	m := headerRe.FindStringSubmatch(src)
+	if m == nil {
+		log.Fatalf("%s: not generated by ryegen", name)
+	}
+	if m[2] != "" {
+		return nil, false
+	}
+	header := "// Code generated by " + m[1] + " and postgen.go; DO NOT EDIT.\n"
+	return &genFile{name, header + src[len(m[0]):]}, true
+}
+
+func (f *genFile) write() {
+	if err := os.WriteFile(f.name, []byte(f.src), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// replace replaces old with new. what names the change for the error if
+// there is no old.
+func (f *genFile) replace(what, old, new string) {
+	if !strings.Contains(f.src, old) {
+		log.Fatalf("%s: %s: nothing to change", f.name, what)
+	}
+	f.src = strings.ReplaceAll(f.src, old, new)
+}
+
+// replaceRegexp replaces the matches of re with repl of the submatches.
+func (f *genFile) replaceRegexp(what string, re *regexp.Regexp, repl func(m []string) string) {
+	n := 0
+	f.src = re.ReplaceAllStringFunc(f.src, func(s string) string {
+		n++
+		return repl(re.FindStringSubmatch(s))
+	})
+	if n == 0 {
+		log.Fatalf("%s: %s: nothing to change", f.name, what)
+	}
+}
+
+// fileImports returns the import paths of a file by alias.
+func fileImports(name string) map[string]string {
+	f, err := parser.ParseFile(fset, name, nil, parser.ImportsOnly)
+	if err != nil {
+		log.Fatal(err)
+	}
+	imports := map[string]string{}
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			imports[imp.Name.Name] = p
+		}
+	}
+	return imports
+}
+
+var callbackRe = regexp.MustCompile(`(\t\tif fn\.Argsn != \d+ \{\n\t\t\treturn nil, _errors\.New\("expected function with \d+ args, but got " \+ objectType\(ps, obj\)\)\n\t\t\}\n)(\t\treturn func\(.*\{\n)`)
+
+// forkCallbacks makes Go callbacks evaluate in a fork of the program state
+// of the script, as Fyne calls them from its own goroutines.
+func forkCallbacks(f *genFile) {
+	f.replaceRegexp("fork callbacks", callbackRe, func(m []string) string {
+		return m[1] + "\t\tbase := forkProgramState(ps)\n" + m[2] + "\t\t\tps := forkProgramState(base)\n"
+	})
+}
+
+// goErrors makes the errors returned by Go functions Rye errors, with the
+// Go error kept.
+func goErrors(f *genFile) {
+	f.replace("go errors", "return _env.NewError(err.Error())", "return goErrorToRye(ps, err)")
+	f.replace("go error values",
+		"func conv_error_toRye(ps *_env.ProgramState, ctx *_env.RyeCtx, s error) (_env.Object, error) {\n\tif s == nil {\n\t\treturn *_env.NewVoid(), nil\n\t}\n",
+		"func conv_error_toRye(ps *_env.ProgramState, ctx *_env.RyeCtx, s error) (_env.Object, error) {\n\tif s == nil {\n\t\treturn *_env.NewVoid(), nil\n\t}\n\tif goErrorFailures.Load() {\n\t\treturn goErrorToRye(ps, s), nil\n\t}\n")
+}
+
+// registerBuiltins registers baseBuiltins, the builtins of rye-fyne.
+func registerBuiltins(f *genFile) {
+	f.replace("register builtins", "\t\t}, ps, \"base\")\n\t\treturn nil\n",
+		"\t\t}, ps, \"base\")\n\t\t_evaldo.RegisterVarBuiltins2(baseBuiltins, ps, \"rye-fyne\")\n\t\treturn nil\n")
+}
+
+var numberRe = regexp.MustCompile(`(func conv_\w+_fromRye\(ps \*_env\.ProgramState, ctx \*_env\.RyeCtx, obj _env\.Object\) \((\w+), error\) \{\n)\tif x, ok := obj\.\(_env\.(Integer|Decimal)\); ok \{\n\t\treturn \w+\(x\.Value\), nil\n\t\}\n`)
+
+// numbers makes the conversions to Go numbers check the range of the
+// type, and accept integers for floats.
+func numbers(f *genFile) {
+	f.replaceRegexp("numbers", numberRe, func(m []string) string {
+		conv := "intFromRye"
+		if m[3] == "Decimal" {
+			conv = "floatFromRye"
+		}
+		return m[1] + "\tif x, ok, err := " + conv + "[" + m[2] + "](obj, \"" + m[2] + "\"); ok {\n\t\treturn x, err\n\t}\n"
+	})
+}
+
+// enumWords makes the enum types passed as words, and the interfaces they
+// implement accept the words.
+func enumWords(f *genFile, enums []*enumType) {
+	aliases := map[string]string{}
+	for alias, p := range fileImports(f.name) {
+		aliases[p] = alias
+	}
+	n := 0
+	insert := func(fn, code string) {
+		if i := strings.Index(f.src, fn); i >= 0 {
+			i += len(fn)
+			f.src = f.src[:i] + code + f.src[i:]
+			n++
+		}
+	}
+	for _, e := range enums {
+		alias, ok := aliases[e.path]
+		if !ok {
+			continue
+		}
+		typ := alias + "." + e.name
+		conv := "conv_" + alias + "_" + e.name
+		insert("func "+conv+"_toRye(ps *_env.ProgramState, ctx *_env.RyeCtx, s "+typ+") (_env.Object, error) {\n",
+			"\tif x, ok := enumToRye(ps, s); ok {\n\t\treturn x, nil\n\t}\n")
+		insert("func "+conv+"_fromRye(ps *_env.ProgramState, ctx *_env.RyeCtx, obj _env.Object) ("+typ+", error) {\n",
+			"\tif x, ok, err := enumFromRye["+typ+"](ps, obj); ok {\n\t\treturn x, err\n\t}\n")
+		for _, iface := range e.ifaces {
+			ityp := alias + "." + iface
+			iconv := "conv_" + alias + "_" + iface
+			insert("func "+iconv+"_toRye(ps *_env.ProgramState, ctx *_env.RyeCtx, s "+ityp+") (_env.Object, error) {\n",
+				"\tif v, ok := s.("+typ+"); ok {\n\t\tif x, ok := enumToRye(ps, v); ok {\n\t\t\treturn x, nil\n\t\t}\n\t}\n")
+			insert("func "+iconv+"_fromRye(ps *_env.ProgramState, ctx *_env.RyeCtx, obj _env.Object) ("+ityp+", error) {\n",
+				"\tif x, ok, err := enumFromRye["+typ+"](ps, obj); ok {\n\t\treturn x, err\n\t}\n")
+		}
+	}
+	if n == 0 {
+		log.Fatalf("%s: enum words: nothing to change", f.name)
+	}
+}
+
+// callbackErrors reports the errors of Rye functions called from Go with
+// reportCallbackError.
+func callbackErrors(f *genFile) {
+	f.replace("callback errors",
+		"\t_fmt.Printf(\"Error: from function %v %v: %v\\n\",\n\t\tfn.Spec.Series.PositionAndSurroundingElements(*ps.Idx),\n\t\tfn.Body.Series.PositionAndSurroundingElements(*ps.Idx),\n\t\terr,\n\t)\n",
+		"\treportCallbackError(ps, fn, err)\n")
+}
+
+// commands makes main run the commands of rye-fyne, with withCommands.
+func commands(f *genFile) {
+	f.replace("commands", "\t_runner.DoMain(func(ps *_env.ProgramState) error {\n",
+		"\t_runner.DoMain(withCommands(func(ps *_env.ProgramState) error {\n")
+	f.replace("commands", "\t\treturn nil\n\t})\n}\n", "\t\treturn nil\n\t}))\n}\n")
+}
+
+// lazyPackages makes the contexts of the Go packages on their first import,
+// with packageContext, registering only the methods on start.
+func lazyPackages(f *genFile) {
+	f.replace("lazy packages", "var packages = map[string]*_env.RyeCtx{}\n\n", "")
+	f.replace("lazy packages",
+		"\t\tfor pkg, builtins := range builtins {\n\t\t\tpackages[pkg] = builtinsContext(ps, builtins, \"gopkg(\" + pkg + \")\")\n\t\t}\n",
+		"\t\tregisterMethods(ps)\n")
+	f.replace("lazy packages", "pkg, ok := packages[arg0.Value]", "pkg, ok := packageContext(ps, arg0.Value)")
+}
+
+// packageName returns the name of a package in Rye, the short name of the
+// set-package rule of ryegen.toml for Fyne, like widget for
+// fyne.io/fyne/v2/widget.
+func packageName(p string) string {
+	if p == "fyne.io/fyne/v2" {
+		return "fyne"
+	}
+	return path.Base(p)
+}
+
+var (
+	pkgLookupRe = regexp.MustCompile(`(\tpkgLookup\["([^"]+)"\] = )"[^"]+"`)
+	kindRe      = regexp.MustCompile(`(^|[^\w./])(\w+)\.([A-Za-z_]\w*)`)
+)
+
+// nativeNames names the packages in the kinds of natives and in the errors
+// as in Rye, like go(*widget/Button) instead of
+// go(*fyne_io_fyne_v2_widget.Button).
+func nativeNames(f *genFile, names map[string]string) {
+	if strings.Contains(f.src, "\tpkgLookup[") {
+		f.replaceRegexp("native names", pkgLookupRe, func(m []string) string {
+			return m[1] + strconv.Quote(packageName(m[2]))
+		})
+		f.replace("native names", "\t\t\ts = t.String()\n",
+			"\t\t\tif name, ok := nativeName(t); ok {\n\t\t\t\ts = name\n\t\t\t} else {\n\t\t\t\ts = t.String()\n\t\t\t}\n")
+		f.replace("native names", autoToNativeSrc, nativeNameSrc)
+	}
+
+	// The string literals after the imports.
+	src := []byte(f.src)
+	var s scanner.Scanner
+	s.Init(fset.AddFile(f.name, -1, len(src)), src, nil, 0)
+	var buf strings.Builder
+	last, n := 0, 0
+	inImport := false
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch tok {
+		case token.IMPORT:
+			inImport = true
+		case token.FUNC, token.VAR, token.TYPE, token.CONST:
+			inImport = false
+		}
+		if tok != token.STRING || inImport {
+			continue
+		}
+		renamed := kindRe.ReplaceAllStringFunc(lit, func(k string) string {
+			m := kindRe.FindStringSubmatch(k)
+			name, ok := names[m[2]]
+			if !ok {
+				return k
+			}
+			n++
+			return m[1] + name + "/" + m[3]
+		})
+		if renamed != lit {
+			off := fset.Position(pos).Offset
+			buf.Write(src[last:off])
+			buf.WriteString(renamed)
+			last = off + len(lit)
+		}
+	}
+	if n == 0 {
+		log.Fatalf("%s: native names: nothing to change", f.name)
+	}
+	buf.Write(src[last:])
+	f.src = buf.String()
+}
+
+const autoToNativeSrc = `// Attempts to create a Ryegen native with the type of v.
+// On failure, returns _env.Native{}, false.
+func autoToNative(ps *_env.ProgramState, v any) (_ _env.Native, ok bool) {
+	t := _reflect.TypeOf(v)
+	if t == nil {
+		return _env.Native{}, false
+	}
+	nPtrs := 0 // level of indirection for the native's name
+	for t.Kind() == _reflect.Pointer {
+		nPtrs++
+		t = t.Elem()
+	}
+	if !isExported(t.Name()) {
+		return _env.Native{}, false
+	}
+	pkg, ok := pkgLookup[t.PkgPath()]
+	if !ok {
+		return _env.Native{}, false
+	}
+	var name _strings.Builder
+	name.WriteString("go(")
+	for i := 0; i < nPtrs; i++ {
+		name.WriteByte('*')
+	}
+	if pkg != "" {
+		name.WriteString(pkg)
+		name.WriteByte('.')
+	}
+	name.WriteString(t.Name())
+	name.WriteString(")")
+	return *_env.NewNative(ps.Idx, v, name.String()), true
+}
+`
+
+const nativeNameSrc = `// Returns the name of a Ryegen native with type t, like go(*widget/Button),
+// with the package named as in Rye.
+// On failure, returns "", false.
+func nativeName(t _reflect.Type) (_ string, ok bool) {
+	if t == nil {
+		return "", false
+	}
+	nPtrs := 0 // level of indirection for the native's name
+	for t.Kind() == _reflect.Pointer {
+		nPtrs++
+		t = t.Elem()
+	}
+	if !isExported(t.Name()) {
+		return "", false
+	}
+	pkg, ok := pkgLookup[t.PkgPath()]
+	if !ok {
+		return "", false
+	}
+	var name _strings.Builder
+	name.WriteString("go(")
+	for i := 0; i < nPtrs; i++ {
+		name.WriteByte('*')
+	}
+	if pkg != "" {
+		name.WriteString(pkg)
+		name.WriteByte('/')
+	}
+	name.WriteString(t.Name())
+	name.WriteString(")")
+	return name.String(), true
+}
+
+// Attempts to create a Ryegen native with the type of v.
+// On failure, returns _env.Native{}, false.
+func autoToNative(ps *_env.ProgramState, v any) (_ _env.Native, ok bool) {
+	name, ok := nativeName(_reflect.TypeOf(v))
+	if !ok {
+		return _env.Native{}, false
+	}
+	return *_env.NewNative(ps.Idx, v, name), true
+}
+`
+
+var argRe = regexp.MustCompile(`(\targ(\d+), err := conv_\w+_fromRye\(ps, ctx, args\[\d+\]\)\n\t\tif err != nil \{\n\t\t\treturn \*_env\.NewVoid\(\), )err\n`)
+
+// argErrors makes the errors converting the arguments of a builtin name the
+// argument.
+func argErrors(f *genFile) {
+	f.replaceRegexp("arg errors", argRe, func(m []string) string {
+		pos, _ := strconv.Atoi(m[2])
+		return m[1] + "&argError{pos: " + strconv.Itoa(pos+1) + ", err: err}\n"
+	})
+}
+
+// enumType is a named integer type of a bound package with constants,
+// passed as words.
+type enumType struct {
+	path, name string
+	// pkgName is the name of the package in Go.
+	pkgName string
+	consts  []enumConst
+	// ifaces are the interfaces of the package the type implements.
+	ifaces []string
+}
+
+type enumConst struct {
+	path, pkgName, name, word string
+}
+
+// loadEnums finds the enum types of the packages bound in ryegen.toml: the
+// named integer types with two or more constants of distinct values, also
+// of other bound packages. The constants are named by their words, the
+// words of the name without those of the type name, like 'medium for
+// widget.MediumImportance. Of constants with the same value the first
+// declared is kept, preferring the package of the type.
+func loadEnums() ([]*enumType, error) {
+	var cfg struct {
+		Source []struct{ Packages []string }
+	}
+	if _, err := toml.DecodeFile("ryegen.toml", &cfg); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, src := range cfg.Source {
+		paths = append(paths, src.Packages...)
+	}
+	sort.Strings(paths)
+	pkgs, err := importPackages(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var enums []*enumType
+	byType := map[*types.Named]*enumType{}
+	for _, p := range paths {
+		scope := pkgs[p].Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !tn.Exported() || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams() != nil {
+				continue
+			}
+			if b, ok := named.Underlying().(*types.Basic); !ok || b.Info()&types.IsInteger == 0 {
+				continue
+			}
+			e := &enumType{path: p, name: name, pkgName: pkgs[p].Name()}
+			enums = append(enums, e)
+			byType[named] = e
+		}
+	}
+
+	consts := map[*enumType][]*types.Const{}
+	for _, p := range paths {
+		scope := pkgs[p].Scope()
+		for _, name := range scope.Names() {
+			c, ok := scope.Lookup(name).(*types.Const)
+			if !ok || !c.Exported() || c.Val().Kind() != constant.Int {
+				continue
+			}
+			if named, ok := c.Type().(*types.Named); ok && byType[named] != nil {
+				consts[byType[named]] = append(consts[byType[named]], c)
+			}
+		}
+	}
+	for _, e := range enums {
+		// The constants of the package of the type first, in the order of
+		// declaration, as the others are usually kept for compatibility.
+		cs := consts[e]
+		sort.SliceStable(cs, func(i, j int) bool {
+			a, b := cs[i], cs[j]
+			if (a.Pkg().Path() == e.path) != (b.Pkg().Path() == e.path) {
+				return a.Pkg().Path() == e.path
+			}
+			if a.Pkg() != b.Pkg() {
+				return a.Pkg().Path() < b.Pkg().Path()
+			}
+			pa, pb := fset.Position(a.Pos()), fset.Position(b.Pos())
+			if pa.Filename != pb.Filename {
+				return pa.Filename < pb.Filename
+			}
+			if pa.Line != pb.Line {
+				return pa.Line < pb.Line
+			}
+			return pa.Column < pb.Column
+		})
+		seen := map[string]bool{}
+		for _, c := range cs {
+			v := c.Val().ExactString()
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			e.consts = append(e.consts, enumConst{c.Pkg().Path(), c.Pkg().Name(), c.Name(), enumWord(e.name, c.Name())})
+		}
+	}
+
+	var res []*enumType
+	for _, e := range enums {
+		if len(e.consts) < 2 {
+			continue
+		}
+		words := map[string]bool{}
+		for _, c := range e.consts {
+			if words[c.word] {
+				return nil, fmt.Errorf("%s.%s: two constants named '%s", e.pkgName, e.name, c.word)
+			}
+			words[c.word] = true
+		}
+		pkg := pkgs[e.path]
+		typ := pkg.Scope().Lookup(e.name).Type()
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !tn.Exported() {
+				continue
+			}
+			iface, ok := tn.Type().Underlying().(*types.Interface)
+			if ok && iface.NumMethods() > 0 && types.Implements(typ, iface) {
+				e.ifaces = append(e.ifaces, name)
+			}
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+// importPackages returns the type information of the packages by import
+// path, read from their export data.
+func importPackages(paths []string) (map[string]*types.Package, error) {
+	args := append([]string{"list", "-export", "-deps", "-json=ImportPath,Export"}, paths...)
+	out, err := exec.Command("go", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	exports := map[string]string{}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var pkg struct{ ImportPath, Export string }
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, err
+		}
+		exports[pkg.ImportPath] = pkg.Export
+	}
+	imp := importer.ForCompiler(fset, "gc", func(p string) (io.ReadCloser, error) {
+		file, ok := exports[p]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", p)
+		}
+		return os.Open(file)
+	})
+	pkgs := map[string]*types.Package{}
+	for _, p := range paths {
+		pkg, err := imp.Import(p)
+		if err != nil {
+			return nil, err
+		}
+		pkgs[p] = pkg
+	}
+	return pkgs, nil
+}
+
+// enumWord returns the word of a constant of an enum type, the kebab case
+// of the name without the words at its start and end that are words of the
+// type name, like medium for MediumImportance of Importance.
+func enumWord(typeName, name string) string {
+	typeWords := camelWords(typeName)
+	words := camelWords(name)
+	isTypeWord := func(w string) bool {
+		for _, t := range typeWords {
+			if sameWord(w, t) {
+				return true
+			}
+		}
+		return false
+	}
+	start, end := 0, len(words)
+	for start < end && isTypeWord(words[start]) {
+		start++
+	}
+	for end > start && isTypeWord(words[end-1]) {
+		end--
+	}
+	if start == end {
+		start, end = 0, len(words)
+	}
+	return strings.ToLower(strings.Join(words[start:end], "-"))
+}
+
+// sameWord reports whether two words are the same but for their ending,
+// like Truncate and Truncation.
+func sameWord(a, b string) bool {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n == len(a) && n == len(b) || n >= 4 && n >= min(len(a), len(b))-1
+}
+
+// camelWords splits a CamelCase name into its words, keeping initialisms
+// like URI together.
+func camelWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		upper := unicode.IsUpper(runes[i]) || unicode.IsDigit(runes[i]) && !unicode.IsDigit(runes[i-1])
+		if upper && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// writeEnums writes enums.gen.go, adding the enum types with addEnum.
+func writeEnums(enums []*enumType) error {
+	imports := map[string]string{}
+	use := func(p, name string) error {
+		if q, ok := imports[name]; ok && q != p {
+			return fmt.Errorf("packages %s and %s both named %s", p, q, name)
+		}
+		imports[name] = p
+		return nil
+	}
+	var body bytes.Buffer
+	for _, e := range enums {
+		if err := use(e.path, e.pkgName); err != nil {
+			return err
+		}
+		fmt.Fprintf(&body, "addEnum(%q, map[%s.%s]string{\n", e.pkgName+"."+e.name, e.pkgName, e.name)
+		for _, c := range e.consts {
+			if err := use(c.path, c.pkgName); err != nil {
+				return err
+			}
+			fmt.Fprintf(&body, "%s.%s: %q,\n", c.pkgName, c.name, c.word)
+		}
+		body.WriteString("})\n")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by postgen.go; DO NOT EDIT.\n\npackage main\n\nimport (\n")
+	var paths []string
+	for _, p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&buf, "%q\n", p)
+	}
+	buf.WriteString(")\n\nfunc init() {\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(enumsOutput, src, 0o644)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"strings"
+	"weak"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+// tableMeasureRows is how many rows are measured to size the columns.
+const tableMeasureRows = 1000
+
+// tableView shows a Rye table in a widget.Table. Rows can be sorted by
+// tapping a column header, which cycles through ascending, descending and
+// the original order.
+type tableView struct {
+	ps      *env.ProgramState
+	table   *widget.Table
+	data    *env.Table
+	order   []int // rows of data in the order they are shown
+	sortCol int   // -1 if not sorted
+	desc    bool
+}
+
+func newTableView(ps *env.ProgramState, data *env.Table) *tableView {
+	v := &tableView{ps: ps, sortCol: -1}
+	v.table = widget.NewTableWithHeaders(
+		func() (int, int) { return len(v.order), len(v.data.Cols) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			l := o.(*widget.Label)
+			cell := v.cell(v.order[id.Row], id.Col)
+			l.SetText(v.text(cell))
+			switch cell.(type) {
+			case env.Integer, env.Decimal:
+				l.Alignment = fyne.TextAlignTrailing
+			default:
+				l.Alignment = fyne.TextAlignLeading
+			}
+		},
+	)
+	v.table.ShowHeaderColumn = false
+	v.table.CreateHeader = func() fyne.CanvasObject {
+		b := widget.NewButton("", nil)
+		b.Importance = widget.LowImportance
+		b.IconPlacement = widget.ButtonIconTrailingText
+		return b
+	}
+	v.table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		b := o.(*widget.Button)
+		if id.Col < 0 || id.Col >= len(v.data.Cols) {
+			b.SetText("")
+			b.SetIcon(nil)
+			b.OnTapped = nil
+			return
+		}
+		b.SetText(v.data.Cols[id.Col])
+		switch {
+		case id.Col != v.sortCol:
+			b.SetIcon(nil)
+		case v.desc:
+			b.SetIcon(theme.MoveDownIcon())
+		default:
+			b.SetIcon(theme.MoveUpIcon())
+		}
+		col := id.Col
+		b.OnTapped = func() { v.tapHeader(col) }
+	}
+	v.setData(data)
+	return v
+}
+
+// setData replaces the shown table, keeping the sort order.
+func (v *tableView) setData(data *env.Table) {
+	v.data = data
+	if v.sortCol >= len(data.Cols) {
+		v.sortCol = -1
+	}
+	v.sort()
+	v.sizeColumns()
+	v.table.Refresh()
+}
+
+func (v *tableView) tapHeader(col int) {
+	switch {
+	case col != v.sortCol:
+		v.sortCol, v.desc = col, false
+	case !v.desc:
+		v.desc = true
+	default:
+		v.sortCol = -1
+	}
+	v.sort()
+	v.table.Refresh()
+}
+
+func (v *tableView) sort() {
+	v.order = make([]int, len(v.data.Rows))
+	for i := range v.order {
+		v.order[i] = i
+	}
+	if v.sortCol < 0 {
+		return
+	}
+	sort.SliceStable(v.order, func(i, j int) bool {
+		a, b := v.cell(v.order[i], v.sortCol), v.cell(v.order[j], v.sortCol)
+		if v.desc {
+			a, b = b, a
+		}
+		return v.less(a, b)
+	})
+}
+
+// sizeColumns makes each column wide enough for its header and the
+// widest of its first cells.
+func (v *tableView) sizeColumns() {
+	style := fyne.TextStyle{}
+	textSize := theme.TextSize()
+	pad := 4 * theme.Padding()
+	for c, name := range v.data.Cols {
+		w := fyne.MeasureText(name, textSize, style).Width + theme.IconInlineSize() + pad
+		for r := 0; r < min(len(v.order), tableMeasureRows); r++ {
+			w = max(w, fyne.MeasureText(v.text(v.cell(r, c)), textSize, style).Width+pad)
+		}
+		v.table.SetColumnWidth(c, w)
+	}
+}
+
+func (v *tableView) cell(row, col int) env.Object {
+	vals := v.data.Rows[row].Values
+	if col >= len(vals) {
+		return nil
+	}
+	return env.ToRyeValue(vals[col])
+}
+
+func (v *tableView) text(obj env.Object) string {
+	if obj == nil {
+		return ""
+	}
+	return obj.Print(*v.ps.Idx)
+}
+
+// less orders numbers numerically and everything else by its text. Numbers
+// come before other values.
+func (v *tableView) less(a, b env.Object) bool {
+	x, xNum := number(a)
+	y, yNum := number(b)
+	switch {
+	case xNum && yNum:
+		return x < y
+	case xNum != yNum:
+		return xNum
+	}
+	return strings.Compare(v.text(a), v.text(b)) < 0
+}
+
+func number(obj env.Object) (float64, bool) {
+	switch x := obj.(type) {
+	case env.Integer:
+		return float64(x.Value), true
+	case env.Decimal:
+		return x.Value, true
+	}
+	return 0, false
+}
+
+func tableFromRye(ps *env.ProgramState, obj env.Object) (*env.Table, bool) {
+	switch x := obj.(type) {
+	case env.Table:
+		return &x, true
+	case *env.Table:
+		return x, true
+	}
+	return nil, false
+}
+
+func init() {
+	builtins_fyne_widget["table-from"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a table widget showing a Rye table, or the table held by the variable named by the word, with sortable column headers.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			if data, ok := tableFromRye(ps, args[0]); ok {
+				nat, _ := autoToNative(ps, newTableView(ps, data).table)
+				return nat
+			}
+			word, ok := args[0].(env.Word)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected table or word naming a variable, but got " + objectType(ps, args[0]))
+			}
+			ctx, err := findVar(ps, word)
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			cur, _ := ctx.GetCurrent(word.Index)
+			data, ok := tableFromRye(ps, cur)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected variable '" + ps.Idx.GetWord(word.Index) + "' to hold a table, but got " + objectType(ps, cur))
+			}
+			v := newTableView(ps, data)
+			// As for list-from, the observer holds the view weakly.
+			wv := weak.Make(v)
+			remove := observeVar(ctx, word.Index, func(ps *env.ProgramState, val env.Object) {
+				v := wv.Value()
+				if v == nil {
+					return
+				}
+				data, ok := tableFromRye(ps, val)
+				if !ok {
+					writeBindingError(ps.Idx.GetWord(word.Index), errors.New("expected table, but got "+objectType(ps, val)))
+					return
+				}
+				fyne.Do(func() { v.setData(data) })
+			})
+			runtime.AddCleanup(v, func(remove func()) { remove() }, remove)
+			nat, _ := autoToNative(ps, v.table)
+			return nat
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tableColumn returns the texts of a column of a table, as shown.
+func tableColumn(tbl *widget.Table, col int) []string {
+	rows, _ := tbl.Length()
+	texts := make([]string, rows)
+	for r := range texts {
+		l := tbl.CreateCell().(*widget.Label)
+		tbl.UpdateCell(widget.TableCellID{Row: r, Col: col}, l)
+		texts[r] = l.Text
+	}
+	return texts
+}
+
+func TestTableSort(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		t: widget/table-from table { "name" "age" } { "b" 30 "c" 4 "a" 100 }
+	`)
+	tbl := testValue[*widget.Table](t, ps, "t")
+	w := test.NewWindow(tbl)
+	defer w.Close()
+	header := tbl.CreateHeader().(*widget.Button)
+	tbl.UpdateHeader(widget.TableCellID{Row: -1, Col: 1}, header)
+	if header.Text != "age" {
+		t.Fatalf("got header %q, want \"age\"", header.Text)
+	}
+
+	// Tapping a header cycles through ascending, descending and the
+	// original order. Numbers are compared as numbers.
+	for _, want := range [][]string{
+		{"b", "c", "a"},
+		{"c", "b", "a"},
+		{"a", "b", "c"},
+		{"b", "c", "a"},
+	} {
+		if got := tableColumn(tbl, 0); !slices.Equal(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		test.Tap(header)
+	}
+}
+
+func TestTableRefresh(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		var 'people table { "name" } { "b" "a" }
+		t: widget/table-from 'people
+	`)
+	tbl := testValue[*widget.Table](t, ps, "t")
+	header := tbl.CreateHeader().(*widget.Button)
+	tbl.UpdateHeader(widget.TableCellID{Row: -1, Col: 0}, header)
+	test.Tap(header)
+
+	evalTest(t, ps, `change! table { "name" } { "d" "c" "e" } 'people`)
+	waitFor(t, "the new rows", func() bool {
+		rows, _ := tbl.Length()
+		return rows == 3
+	})
+	if got, want := tableColumn(tbl, 0), []string{"c", "d", "e"}; !slices.Equal(got, want) {
+		t.Errorf("got %q after the change, want %q sorted", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/refaktor/rye/env"
+)
+
+// Golden files are kept in the testdata directory next to the test file, as
+// with Fyne's own assertions. When a rendering doesn't match, or there is no
+// golden file yet, it is written to testdata/failed so it can be inspected
+// and used as the new golden file. With `rye-fyne test -update` the golden
+// files are written instead.
+
+// updateGoldens is set by the -update flag of the test command.
+var updateGoldens bool
+
+func init() {
+	m := builtins_fyne_test
+	m["assert-image"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Fails if a window, canvas or canvas object doesn't render to the same image as the PNG file of that name in testdata.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return assertGolden(ps, args[0], args[1], func(c fyne.Canvas) ([]byte, error) {
+				var buf bytes.Buffer
+				err := png.Encode(&buf, c.Capture())
+				return buf.Bytes(), err
+			}, imagesEqual)
+		},
+	}
+	m["assert-markup"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Fails if a window, canvas or canvas object doesn't render to the same markup as the XML file of that name in testdata.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return assertGolden(ps, args[0], args[1], func(c fyne.Canvas) ([]byte, error) {
+				return []byte(test.RenderToMarkup(c)), nil
+			}, func(golden, got []byte) (bool, error) {
+				return strings.ReplaceAll(string(golden), "\r", "") == string(got), nil
+			})
+		},
+	}
+}
+
+// assertGolden renders target with render and compares the result with the
+// golden file name using equal.
+func assertGolden(ps *env.ProgramState, target, name env.Object, render func(fyne.Canvas) ([]byte, error), equal func(golden, got []byte) (bool, error)) env.Object {
+	file, ok := name.(env.String)
+	if !ok {
+		ps.FailureFlag = true
+		return env.NewError("expected file name, but got " + objectType(ps, name))
+	}
+	c, err := canvasFromRye(ps, target)
+	if err == nil {
+		err = compareGolden(file.Value, c, render, equal)
+	}
+	if err != nil {
+		ps.FailureFlag = true
+		return env.NewError(err.Error())
+	}
+	return *env.NewBoolean(true)
+}
+
+func compareGolden(name string, c fyne.Canvas, render func(fyne.Canvas) ([]byte, error), equal func(golden, got []byte) (bool, error)) error {
+	got, err := render(c)
+	if err != nil {
+		return err
+	}
+	goldenPath := filepath.Join("testdata", name)
+	failedPath := filepath.Join("testdata", "failed", name)
+	if updateGoldens {
+		os.Remove(failedPath)
+		return writeGolden(goldenPath, got)
+	}
+	golden, err := os.ReadFile(goldenPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := writeGolden(failedPath, got); err != nil {
+			return err
+		}
+		return errors.New("golden file " + goldenPath + " not found, rendering written to " + failedPath + ", run with -update to create it")
+	}
+	if err != nil {
+		return err
+	}
+	same, err := equal(golden, got)
+	if err != nil {
+		return errors.New(goldenPath + ": " + err.Error())
+	}
+	if !same {
+		if err := writeGolden(failedPath, got); err != nil {
+			return err
+		}
+		return errors.New("rendering doesn't match " + goldenPath + ", it was written to " + failedPath)
+	}
+	// A fixed rendering leaves no stale failure behind.
+	os.Remove(failedPath)
+	return nil
+}
+
+func writeGolden(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// imagesEqual compares the pixels of two PNG images.
+func imagesEqual(golden, got []byte) (bool, error) {
+	a, err := png.Decode(bytes.NewReader(golden))
+	if err != nil {
+		return false, err
+	}
+	b, err := png.Decode(bytes.NewReader(got))
+	if err != nil {
+		return false, err
+	}
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return false, nil
+	}
+	return bytes.Equal(nrgbaPixels(a), nrgbaPixels(b)), nil
+}
+
+func nrgbaPixels(img image.Image) []byte {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) && n.Stride == 4*n.Rect.Dx() {
+		return n.Pix
+	}
+	n := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(n, n.Bounds(), img, img.Bounds().Min, draw.Src)
+	return n.Pix
+}
+
+// canvasFromRye returns the canvas of a window, a canvas, the canvas of the
+// window showing a canvas object, or a new test canvas showing a canvas
+// object that isn't shown, at its current or minimum size. An object that is
+// shown isn't put on a new canvas, as that would move and resize it in its
+// window.
+func canvasFromRye(ps *env.ProgramState, obj env.Object) (fyne.Canvas, error) {
+	if nat, ok := obj.(env.Native); ok {
+		switch x := nat.Value.(type) {
+		case fyne.Window:
+			return x.Canvas(), nil
+		case fyne.Canvas:
+			return x, nil
+		}
+	}
+	o, err := conv_fyne_io_fyne_v2_CanvasObject_fromRye(ps, nil, obj)
+	if err != nil || o == nil {
+		return nil, errors.New("expected window, canvas or canvas object, but got " + objectType(ps, obj))
+	}
+	if c := shownCanvas(o); c != nil {
+		return c, nil
+	}
+	c := test.NewCanvas()
+	c.SetPadded(false)
+	size := o.MinSize().Max(o.Size())
+	c.SetContent(o)
+	c.Resize(size)
+	return c, nil
+}
+
+// shownCanvas returns the canvas of the window showing o, or nil. The
+// CanvasForObject of the test driver can't be used, as it returns the last
+// window for any object.
+func shownCanvas(o fyne.CanvasObject) fyne.Canvas {
+	for _, w := range fyne.CurrentApp().Driver().AllWindows() {
+		c := w.Canvas()
+		for _, root := range append([]fyne.CanvasObject{c.Content()}, c.Overlays().List()...) {
+			if root != nil && walkObjects(root, func(x fyne.CanvasObject) bool { return x == o }) {
+				return c
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestCanvasFromRyeKeepsShownObject(t *testing.T) {
+	ps := testProgramState(t)
+	b := widget.NewButton("b", nil)
+	w := test.NewWindow(container.NewVBox(widget.NewLabel("l"), b))
+	defer w.Close()
+	w.Resize(fyne.NewSize(200, 200))
+	pos, size := b.Position(), b.Size()
+
+	c, err := canvasFromRye(ps, *env.NewNative(ps.Idx, b, "go(*widget/Button)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != w.Canvas() {
+		t.Error("the canvas of the window isn't used")
+	}
+	if b.Position() != pos || b.Size() != size {
+		t.Errorf("got the button at %v with size %v, want %v with %v", b.Position(), b.Size(), pos, size)
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// `rye-fyne test [-update] [path ...]` runs GUI tests headless with the fyne/test
+// driver. Paths are test files, or directories searched for files ending in
+// _test.rye. Each file is evaluated in its directory with app/new returning
+// a test app, then every function without arguments whose name starts with
+// test- is called, in the order of their names. A test fails if it fails or
+// errors, like a failed assert does.
+
+// testFileSuffix marks test files in directories.
+const testFileSuffix = "_test.rye"
+
+// widgetNames maps objects to the names the layout dialect gave them with
+// set-words, for test/find-by-name.
+var widgetNames objectMap[string]
+
+// testResult is the outcome of a test function or of evaluating a file.
+type testResult struct {
+	name     string
+	err      error
+	duration time.Duration
+}
+
+func init() {
+	commands["test"] = runTests
+
+	m := builtins_fyne_test
+	m["find-by-text"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Returns the first object in a window or canvas object whose text is the string, like a button or label.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return findObject(ps, args[0], args[1], "text", func(o fyne.CanvasObject, s string) bool {
+				text, ok := objectText(o)
+				return ok && text == s
+			})
+		},
+	}
+	m["find-by-name"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Returns the object in a window or canvas object that a set-word of the layout dialect named.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return findObject(ps, args[0], args[1], "name", func(o fyne.CanvasObject, s string) bool {
+				name, ok := widgetNames.load(o)
+				return ok && name == s
+			})
+		},
+	}
+	m["drag-object"] = &env.VarBuiltin{
+		Argsn: 3,
+		Doc:   "Drags a draggable object, like a slider or split, by the given distance.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			obj, err := conv_fyne_io_fyne_v2_CanvasObject_fromRye(ps, nil, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			d, ok := obj.(fyne.Draggable)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected draggable object, but got " + objectType(ps, args[0]))
+			}
+			var delta [2]float32
+			for i, arg := range args[1:] {
+				v, ok := number(arg)
+				if !ok {
+					ps.FailureFlag = true
+					return env.NewError("expected number, but got " + objectType(ps, arg))
+				}
+				delta[i] = float32(v)
+			}
+			pos := fyne.NewPos(obj.Size().Width/2, obj.Size().Height/2)
+			d.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: pos}, Dragged: fyne.NewDelta(delta[0], delta[1])})
+			d.DragEnd()
+			return args[0]
+		},
+	}
+}
+
+// runTests is the test command.
+func runTests(regfn func(*env.ProgramState) error, args []string) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.BoolVar(&updateGoldens, "update", false, "Write the golden files of test/assert-image and test/assert-markup instead of comparing")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	files, err := testFiles(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "no test files found in "+strings.Join(args, " "))
+		return 2
+	}
+	useTestApp()
+	evaldo.NoInspectMode = true
+
+	failed := 0
+	start := time.Now()
+	for _, file := range files {
+		fmt.Println("=== " + file)
+		for _, r := range runTestFile(regfn, file) {
+			if r.err != nil {
+				failed++
+				fmt.Printf("--- FAIL: %s (%.2fs)\n", r.name, r.duration.Seconds())
+				fmt.Println("    " + strings.ReplaceAll(r.err.Error(), "\n", "\n    "))
+			} else {
+				fmt.Printf("--- PASS: %s (%.2fs)\n", r.name, r.duration.Seconds())
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("FAIL\t%d failed\t%.2fs\n", failed, time.Since(start).Seconds())
+		return 1
+	}
+	fmt.Printf("ok\t%.2fs\n", time.Since(start).Seconds())
+	return 0
+}
+
+// testFiles returns the files given and the test files in the directories
+// given.
+func testFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && strings.HasSuffix(p, testFileSuffix) {
+				files = append(files, p)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// useTestApp makes app/new and app/new-with-id return apps of the test
+// driver.
+func useTestApp() {
+	newApp := func(ps *env.ProgramState) env.Object {
+		obj, _ := conv_fyne_io_fyne_v2_App_toRye(ps, nil, test.NewApp())
+		return obj
+	}
+	builtins_fyne_app["new"] = &env.VarBuiltin{
+		Argsn: 0,
+		Doc:   "Creates a new app of the test driver.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return newApp(ps)
+		},
+	}
+	builtins_fyne_app["new-with-id"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a new app of the test driver, ignoring the ID.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return newApp(ps)
+		},
+	}
+}
+
+// runTestFile evaluates file and runs its test functions, in the directory
+// of the file so it can load the scripts next to it.
+func runTestFile(regfn func(*env.ProgramState) error, file string) []testResult {
+	start := time.Now()
+	wd, err := os.Getwd()
+	if err == nil {
+		err = os.Chdir(filepath.Dir(file))
+		defer os.Chdir(wd)
+	}
+	var ps *env.ProgramState
+	if err == nil {
+		ps, err = newProgramState(regfn, filepath.Base(file), nil)
+	}
+	if err == nil {
+		ps, err = evalFile(ps, filepath.Base(file))
+	}
+	if err != nil {
+		return []testResult{{name: filepath.Base(file), err: err, duration: time.Since(start)}}
+	}
+	var results []testResult
+	for _, name := range testFunctions(ps) {
+		start := time.Now()
+		err := runTestFunction(ps, name)
+		results = append(results, testResult{name: name, err: err, duration: time.Since(start)})
+	}
+	if len(results) == 0 {
+		results = append(results, testResult{name: filepath.Base(file), duration: time.Since(start)})
+	}
+	return results
+}
+
+// testFunctions returns the names of the test functions of the file
+// evaluated in ps.
+func testFunctions(ps *env.ProgramState) []string {
+	var names []string
+	for idx, obj := range ps.Ctx.GetState() {
+		name := ps.Idx.GetWord(idx)
+		if fn, ok := obj.(env.Function); ok && fn.Argsn == 0 && strings.HasPrefix(name, "test-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runTestFunction(base *env.ProgramState, name string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	idx, _ := base.Idx.GetIndex(name)
+	obj, _ := base.Ctx.Get(idx)
+	ps := forkProgramState(base)
+	evaldo.CallFunctionArgsN(obj.(env.Function), ps, nil)
+	if ps.ErrorFlag || ps.FailureFlag {
+		return errors.New(resultMessage(ps))
+	}
+	if _, ok := ps.Res.(*env.Error); ok {
+		return errors.New(resultMessage(ps))
+	}
+	return nil
+}
+
+// findObject returns the first object in the window or canvas object root,
+// in the order they are drawn, that match reports as matching the string.
+// The overlays of a window, like dialogs and pop-ups, are searched too.
+func findObject(ps *env.ProgramState, root, str env.Object, what string, match func(fyne.CanvasObject, string) bool) env.Object {
+	s, ok := str.(env.String)
+	if !ok {
+		ps.FailureFlag = true
+		return env.NewError("expected string, but got " + objectType(ps, str))
+	}
+	var objs []fyne.CanvasObject
+	if nat, ok := root.(env.Native); ok {
+		if w, ok := nat.Value.(fyne.Window); ok {
+			objs = append([]fyne.CanvasObject{w.Canvas().Content()}, w.Canvas().Overlays().List()...)
+		}
+	}
+	if objs == nil {
+		obj, err := conv_fyne_io_fyne_v2_CanvasObject_fromRye(ps, nil, root)
+		if err != nil || obj == nil {
+			ps.FailureFlag = true
+			return env.NewError("expected window or canvas object, but got " + objectType(ps, root))
+		}
+		objs = []fyne.CanvasObject{obj}
+	}
+	var found fyne.CanvasObject
+	for _, obj := range objs {
+		if walkObjects(obj, func(o fyne.CanvasObject) bool {
+			if match(o, s.Value) {
+				found = o
+			}
+			return found != nil
+		}) {
+			break
+		}
+	}
+	if found == nil {
+		ps.FailureFlag = true
+		return env.NewError("no object with " + what + " \"" + s.Value + "\"")
+	}
+	res, _ := conv_fyne_io_fyne_v2_CanvasObject_toRye(ps, nil, found)
+	return res
+}
+
+// walkObjects calls fn for o and the objects it contains, including those
+// drawn by widget renderers, until fn returns true.
+func walkObjects(o fyne.CanvasObject, fn func(fyne.CanvasObject) bool) bool {
+	if fn(o) {
+		return true
+	}
+	for _, c := range objectChildren(o) {
+		if walkObjects(c, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectChildren returns the objects of a container, or those a widget is
+// drawn with. find-by-text and the inspector deliberately rely on
+// test.WidgetRenderer: it is the only way outside of Fyne to the renderer
+// a widget is drawn with, in the test driver and in the running app alike.
+// A renderer made with CreateRenderer would have objects of its own, not
+// the ones drawn.
+func objectChildren(o fyne.CanvasObject) []fyne.CanvasObject {
+	switch x := o.(type) {
+	case *fyne.Container:
+		return x.Objects
+	case fyne.Widget:
+		return test.WidgetRenderer(x).Objects()
+	}
+	return nil
+}
+
+// objectText returns the Text field of objects like buttons, labels, entries
+// and canvas texts.
+func objectText(o fyne.CanvasObject) (string, bool) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return "", false
+	}
+	f := v.Elem().FieldByName("Text")
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return "", false
+	}
+	return f.String(), true
+}
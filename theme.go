@@ -0,0 +1,463 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/BurntSushi/toml"
+	"github.com/refaktor/rye/env"
+)
+
+// themeSpec holds the overrides of a theme created by theme/from-dict or
+// theme/load. A spec looks like:
+//
+//	colors: { primary: "#ff8800" background: { light: "#ffffff" dark: "#202020" } }
+//	sizes:  { padding: 6 text: 15 }
+//	fonts:  { regular: "Inter.ttf" bold: "Inter-Bold.ttf" monospace: "Mono.ttf" }
+//	icons:  { home: "home.svg" }
+//
+// Names can be given as in Fyne, like inputBackground, or as input-background.
+type themeSpec struct {
+	colors map[fyne.ThemeColorName]themeColor
+	sizes  map[fyne.ThemeSizeName]float32
+	fonts  map[string]fyne.Resource
+	icons  map[fyne.ThemeIconName]fyne.Resource
+}
+
+// themeColor is a color per variant, nil for the default one.
+type themeColor struct {
+	light, dark color.Color
+}
+
+// themeFonts are the keys of the fonts section.
+var themeFonts = []string{"regular", "bold", "italic", "bold-italic", "monospace", "symbol"}
+
+// ryeTheme is a fyne.Theme that falls back to the default theme for
+// everything its spec doesn't override. The spec of a loaded theme is
+// replaced when its file changes.
+type ryeTheme struct {
+	mu   sync.RWMutex
+	spec *themeSpec
+
+	applied bool // guarded by themeWatchesMu
+}
+
+func (t *ryeTheme) current() *themeSpec {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.spec
+}
+
+func (t *ryeTheme) Color(n fyne.ThemeColorName, v fyne.ThemeVariant) color.Color {
+	if c, ok := t.current().colors[n]; ok {
+		if v == theme.VariantDark && c.dark != nil {
+			return c.dark
+		}
+		if v != theme.VariantDark && c.light != nil {
+			return c.light
+		}
+	}
+	return theme.DefaultTheme().Color(n, v)
+}
+
+func (t *ryeTheme) Font(s fyne.TextStyle) fyne.Resource {
+	if r, ok := t.current().fonts[themeFontKey(s)]; ok {
+		return r
+	}
+	return theme.DefaultTheme().Font(s)
+}
+
+func (t *ryeTheme) Icon(n fyne.ThemeIconName) fyne.Resource {
+	if r, ok := t.current().icons[n]; ok {
+		return r
+	}
+	return theme.DefaultTheme().Icon(n)
+}
+
+func (t *ryeTheme) Size(n fyne.ThemeSizeName) float32 {
+	if s, ok := t.current().sizes[n]; ok {
+		return s
+	}
+	return theme.DefaultTheme().Size(n)
+}
+
+func themeFontKey(s fyne.TextStyle) string {
+	switch {
+	case s.Monospace:
+		return "monospace"
+	case s.Symbol:
+		return "symbol"
+	case s.Bold && s.Italic:
+		return "bold-italic"
+	case s.Bold:
+		return "bold"
+	case s.Italic:
+		return "italic"
+	}
+	return "regular"
+}
+
+// themeName turns a name like input-background into Fyne's inputBackground.
+func themeName(s string) string {
+	parts := strings.Split(s, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// parseThemeSpec reads a spec decoded from Rye, JSON or TOML. Paths of fonts
+// and icons are relative to dir.
+func parseThemeSpec(m map[string]any, dir string) (*themeSpec, error) {
+	spec := &themeSpec{
+		colors: map[fyne.ThemeColorName]themeColor{},
+		sizes:  map[fyne.ThemeSizeName]float32{},
+		fonts:  map[string]fyne.Resource{},
+		icons:  map[fyne.ThemeIconName]fyne.Resource{},
+	}
+	for section, v := range m {
+		switch section {
+		case "colors", "sizes", "fonts", "icons":
+		default:
+			return nil, fmt.Errorf("unknown section '%s', expected colors, sizes, fonts or icons", section)
+		}
+		entries, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected section '%s' to be a dict", section)
+		}
+		for name, v := range entries {
+			var err error
+			switch section {
+			case "colors":
+				spec.colors[fyne.ThemeColorName(themeName(name))], err = parseThemeColor(v)
+			case "sizes":
+				spec.sizes[fyne.ThemeSizeName(themeName(name))], err = parseThemeSize(v)
+			case "fonts":
+				if !isThemeFont(name) {
+					return nil, fmt.Errorf("unknown font '%s', expected one of %s", name, strings.Join(themeFonts, ", "))
+				}
+				spec.fonts[name], err = loadThemeResource(v, dir)
+			case "icons":
+				spec.icons[fyne.ThemeIconName(themeName(name))], err = loadThemeResource(v, dir)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s '%s': %w", strings.TrimSuffix(section, "s"), name, err)
+			}
+		}
+	}
+	return spec, nil
+}
+
+func isThemeFont(name string) bool {
+	for _, f := range themeFonts {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseThemeColor reads a color, or a dict with a color for the light and
+// the dark variant.
+func parseThemeColor(v any) (themeColor, error) {
+	variants, ok := v.(map[string]any)
+	if !ok {
+		c, err := parseColor(v)
+		return themeColor{c, c}, err
+	}
+	var tc themeColor
+	for variant, v := range variants {
+		c, err := parseColor(v)
+		if err != nil {
+			return tc, err
+		}
+		switch variant {
+		case "light":
+			tc.light = c
+		case "dark":
+			tc.dark = c
+		default:
+			return tc, fmt.Errorf("unknown variant '%s', expected light or dark", variant)
+		}
+	}
+	return tc, nil
+}
+
+// parseColor reads a color.Color or a hex string like #rgb, #rgba, #rrggbb
+// or #rrggbbaa.
+func parseColor(v any) (color.Color, error) {
+	if c, ok := v.(color.Color); ok {
+		return c, nil
+	}
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "#") {
+		return nil, fmt.Errorf("expected color like \"#ff8800\", but got %v", v)
+	}
+	hex := s[1:]
+	if len(hex) == 3 || len(hex) == 4 {
+		var b strings.Builder
+		for _, r := range hex {
+			b.WriteRune(r)
+			b.WriteRune(r)
+		}
+		hex = b.String()
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return nil, fmt.Errorf("expected color like \"#ff8800\", but got \"%s\"", s)
+	}
+	return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+func parseThemeSize(v any) (float32, error) {
+	switch x := v.(type) {
+	case int64:
+		return float32(x), nil
+	case float64:
+		return float32(x), nil
+	}
+	return 0, fmt.Errorf("expected number, but got %v", v)
+}
+
+func loadThemeResource(v any, dir string) (fyne.Resource, error) {
+	if r, ok := v.(fyne.Resource); ok {
+		return r, nil
+	}
+	path, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected path, but got %v", v)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return fyne.LoadResourceFromPath(path)
+}
+
+// themeValueFromRye converts a Rye dict to the maps parseThemeSpec reads.
+// Dicts may hold Rye values or, like those from parse-json, Go values.
+func themeValueFromRye(v any) any {
+	switch x := v.(type) {
+	case env.Dict:
+		m := make(map[string]any, len(x.Data))
+		for k, v := range x.Data {
+			m[k] = themeValueFromRye(v)
+		}
+		return m
+	case map[string]any:
+		m := make(map[string]any, len(x))
+		for k, v := range x {
+			m[k] = themeValueFromRye(v)
+		}
+		return m
+	case env.String:
+		return x.Value
+	case env.Integer:
+		return x.Value
+	case env.Decimal:
+		return x.Value
+	case env.Native:
+		return x.Value
+	case int:
+		return int64(x)
+	}
+	return v
+}
+
+// readThemeFile reads a JSON or TOML theme file, depending on its extension.
+func readThemeFile(path string) (*themeSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err = json.Unmarshal(data, &m); err == nil {
+			jsonNumbers(m)
+		}
+	case ".toml":
+		err = toml.Unmarshal(data, &m)
+	default:
+		return nil, errors.New("expected .json or .toml theme file, but got " + path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseThemeSpec(m, filepath.Dir(path))
+}
+
+// jsonNumbers turns the whole float64 numbers of decoded JSON into int64,
+// like TOML and Rye integers.
+func jsonNumbers(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, v := range x {
+			x[k] = jsonNumbers(v)
+		}
+	case float64:
+		if x == float64(int64(x)) {
+			return int64(x)
+		}
+	}
+	return v
+}
+
+// watchFile calls changed after path was written, created or replaced,
+// until stop is called. It watches the directory, as editors often save by
+// replacing the file.
+func watchFile(path string, changed func()) (stop func(), err error) {
+	path = filepath.Clean(path)
+	return watchFiles([]string{filepath.Dir(path)}, func(name string) bool {
+		return name == path
+	}, changed)
+}
+
+// themeWatches stop watching the files of loaded themes. A theme's file is
+// watched until the theme was the app's theme and got replaced, or the app
+// quits. themeWatchesApp is the app whose settings are listened to.
+var (
+	themeWatchesMu  sync.Mutex
+	themeWatches    = map[*ryeTheme]func(){}
+	themeWatchesApp fyne.App
+)
+
+// watchTheme reloads t when its file at path changes.
+func watchTheme(t *ryeTheme, path string) error {
+	stop, err := watchFile(path, func() { t.reload(path) })
+	if err != nil {
+		return err
+	}
+	themeWatchesMu.Lock()
+	defer themeWatchesMu.Unlock()
+	themeWatches[t] = stop
+	if a := fyne.CurrentApp(); a != nil && a != themeWatchesApp {
+		themeWatchesApp = a
+		a.Settings().AddListener(stopReplacedThemes)
+	}
+	return nil
+}
+
+// stopReplacedThemes stops watching the files of themes that were the
+// app's theme before the settings changed.
+func stopReplacedThemes(s fyne.Settings) {
+	current := s.Theme()
+	themeWatchesMu.Lock()
+	defer themeWatchesMu.Unlock()
+	for t, stop := range themeWatches {
+		switch {
+		case fyne.Theme(t) == current:
+			t.applied = true
+		case t.applied:
+			stop()
+			delete(themeWatches, t)
+		}
+	}
+}
+
+// stopThemeWatches stops watching the files of all loaded themes, once the
+// app quit.
+func stopThemeWatches() {
+	themeWatchesMu.Lock()
+	defer themeWatchesMu.Unlock()
+	for t, stop := range themeWatches {
+		stop()
+		delete(themeWatches, t)
+	}
+}
+
+// stopThemesOnQuit makes app .run and window .show-and-run stop watching
+// theme files once they return. It wraps the generated builtins, so it is
+// called once the builtins are registered.
+func stopThemesOnQuit() {
+	m := builtins_fyne
+	for _, name := range []string{"go(fyne/App)//run", "go(fyne/Window)//show-and-run"} {
+		run := m[name]
+		m[name] = &env.VarBuiltin{
+			Argsn: run.Argsn,
+			Doc:   run.Doc,
+			Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+				defer stopThemeWatches()
+				return run.Fn(ps, args...)
+			},
+		}
+	}
+}
+
+// reload reads the theme file again and applies it if it is the app's
+// current theme. If the file is invalid, the theme stays as it was.
+func (t *ryeTheme) reload(path string) {
+	spec, err := readThemeFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in theme '%s': %s\n", path, err)
+		return
+	}
+	t.mu.Lock()
+	t.spec = spec
+	t.mu.Unlock()
+	fyne.Do(func() {
+		if a := fyne.CurrentApp(); a != nil && a.Settings().Theme() == fyne.Theme(t) {
+			a.Settings().SetTheme(t)
+		}
+	})
+}
+
+func init() {
+	m := builtins_fyne_theme
+	m["from-dict"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a theme from a dict of colors, sizes, fonts and icons, using the default theme for everything else.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			d, ok := args[0].(env.Dict)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected dict, but got " + objectType(ps, args[0]))
+			}
+			spec, err := parseThemeSpec(themeValueFromRye(d).(map[string]any), "")
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			return *env.NewNative(ps.Idx, fyne.Theme(&ryeTheme{spec: spec}), "go(fyne/Theme)")
+		},
+	}
+	m["load"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a theme from a JSON or TOML file of colors, sizes, fonts and icons. The theme is updated when the file changes.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			var path string
+			switch x := args[0].(type) {
+			case env.String:
+				path = x.Value
+			case env.Uri:
+				path = x.Path
+			default:
+				ps.FailureFlag = true
+				return env.NewError("expected path, but got " + objectType(ps, args[0]))
+			}
+			spec, err := readThemeFile(path)
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			t := &ryeTheme{spec: spec}
+			if err := watchTheme(t, path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error watching '%s': %s\n", path, err)
+			}
+			return *env.NewNative(ps.Idx, fyne.Theme(t), "go(fyne/Theme)")
+		},
+	}
+}
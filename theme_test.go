@@ -0,0 +1,127 @@
+package main
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+func TestReadThemeFile(t *testing.T) {
+	files := map[string]string{
+		"theme.json": `{
+			"colors": {"primary": "#f80", "input-background": {"light": "#ffffff", "dark": "#20202080"}},
+			"sizes": {"padding": 6, "text": 14.5}
+		}`,
+		"theme.toml": `
+			[colors]
+			primary = "#ff8800"
+			input-background = { light = "#fff", dark = "#20202080" }
+			[sizes]
+			padding = 6
+			text = 14.5
+		`,
+	}
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			spec, err := readThemeFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c := spec.colors[theme.ColorNamePrimary]; c.light != (color.NRGBA{0xff, 0x88, 0, 0xff}) || c.dark != c.light {
+				t.Errorf("got primary %v, want #ff8800 in both variants", c)
+			}
+			bg := spec.colors[theme.ColorNameInputBackground]
+			if bg.light != (color.NRGBA{0xff, 0xff, 0xff, 0xff}) || bg.dark != (color.NRGBA{0x20, 0x20, 0x20, 0x80}) {
+				t.Errorf("got input background %v, want #ffffff and #20202080", bg)
+			}
+			if spec.sizes[theme.SizeNamePadding] != 6 || spec.sizes[theme.SizeNameText] != 14.5 {
+				t.Errorf("got sizes %v, want padding 6 and text 14.5", spec.sizes)
+			}
+		})
+	}
+}
+
+func TestParseThemeSpecErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[string]any
+		want string
+	}{
+		{"section", map[string]any{"shapes": map[string]any{}}, "unknown section 'shapes'"},
+		{"section type", map[string]any{"colors": "#fff"}, "expected section 'colors' to be a dict"},
+		{"variant", map[string]any{"colors": map[string]any{"primary": map[string]any{"dim": "#fff"}}}, "color 'primary': unknown variant 'dim'"},
+		{"size", map[string]any{"sizes": map[string]any{"padding": "6"}}, "size 'padding': expected number"},
+		{"font", map[string]any{"fonts": map[string]any{"light": "a.ttf"}}, "unknown font 'light'"},
+		{"font file", map[string]any{"fonts": map[string]any{"bold": "missing.ttf"}}, "font 'bold':"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseThemeSpec(tt.spec, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for _, s := range []any{"ff8800", "#", "#ff", "#ff880", "#ff8800aa00", "#gg8800", "#-f8800", 16746496, nil} {
+		if c, err := parseColor(s); err == nil {
+			t.Errorf("got %v for %#v, want an error", c, s)
+		}
+	}
+	for s, want := range map[string]color.NRGBA{
+		"#f80":      {0xff, 0x88, 0, 0xff},
+		"#f808":     {0xff, 0x88, 0, 0x88},
+		"#FF8800":   {0xff, 0x88, 0, 0xff},
+		"#ff880080": {0xff, 0x88, 0, 0x80},
+	} {
+		if c, err := parseColor(s); err != nil || c != want {
+			t.Errorf("got %v, %v for %q, want %v", c, err, s, want)
+		}
+	}
+}
+
+func TestThemeWatchStopped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.json")
+	if err := os.WriteFile(path, []byte(`{"sizes": {"padding": 6}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		th: theme/load "`+filepath.ToSlash(path)+`"
+	`)
+	th := testValue[fyne.Theme](t, ps, "th").(*ryeTheme)
+	watched := func() bool {
+		themeWatchesMu.Lock()
+		defer themeWatchesMu.Unlock()
+		_, ok := themeWatches[th]
+		return ok
+	}
+	settings := fyne.CurrentApp().Settings()
+	settings.SetTheme(th)
+
+	// The applied theme follows its file.
+	if err := os.WriteFile(path, []byte(`{"sizes": {"padding": 9}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the reload", func() bool { return th.Size(theme.SizeNamePadding) == 9 })
+	if !watched() {
+		t.Fatal("the file of the applied theme isn't watched")
+	}
+
+	settings.SetTheme(test.Theme())
+	if watched() {
+		t.Error("the file of a replaced theme is still watched")
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"weak"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+	"github.com/refaktor/rye/evaldo"
+)
+
+// treeNode is a value in a nested Rye structure shown by tree-from. Its key
+// is a string for dict entries, a word for context entries and an integer
+// for block and list items.
+type treeNode struct {
+	key   env.Object
+	value env.Object
+	path  []env.Object
+}
+
+// treeView shows a nested structure of dicts, contexts, blocks and lists
+// in a widget.Tree. The UID of a node is built from the keys on its path,
+// each prefixed with its length, so it stays the same when the structure is
+// replaced and any string can be a key. Children are only looked up when a
+// branch is opened.
+type treeView struct {
+	ps    *env.ProgramState
+	tree  *widget.Tree
+	nodes map[widget.TreeNodeID]*treeNode
+
+	// mu guards onSelected, which the script sets while fyne reads it on
+	// the main goroutine.
+	mu         sync.Mutex
+	onSelected *env.Function
+}
+
+// treeViews maps trees created by tree-from to their views. The views are
+// held weakly too, as they reference their trees.
+var treeViews objectMap[weak.Pointer[treeView]]
+
+func newTreeView(ps *env.ProgramState, root env.Object) *treeView {
+	v := &treeView{ps: ps}
+	v.setRoot(root)
+	v.tree = widget.NewTree(
+		v.childUIDs,
+		func(uid widget.TreeNodeID) bool {
+			n, ok := v.node(uid)
+			return ok && isTreeBranch(n.value)
+		},
+		func(branch bool) fyne.CanvasObject { return widget.NewLabel("") },
+		func(uid widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
+			n, ok := v.node(uid)
+			if !ok {
+				return
+			}
+			o.(*widget.Label).SetText(v.label(n, branch))
+		},
+	)
+	v.tree.OnSelected = func(uid widget.TreeNodeID) {
+		v.mu.Lock()
+		fn := v.onSelected
+		v.mu.Unlock()
+		n, ok := v.node(uid)
+		if !ok || fn == nil {
+			return
+		}
+		ps := forkProgramState(v.ps)
+		path := make([]env.Object, len(n.path))
+		copy(path, n.path)
+		evaldo.CallFunctionArgsN(*fn, ps, nil, *env.NewBlock(*env.NewTSeries(path)), n.value)
+		if e, ok := ps.Res.(*env.Error); ok {
+			showFunctionError(ps, *fn, errors.New(e.Message))
+		}
+	}
+	treeViews.store(v.tree, weak.Make(v))
+	return v
+}
+
+// setRoot replaces the shown structure. Open branches stay open if they
+// still exist.
+func (v *treeView) setRoot(root env.Object) {
+	v.nodes = map[widget.TreeNodeID]*treeNode{"": {value: root}}
+}
+
+func (v *treeView) childUIDs(uid widget.TreeNodeID) []widget.TreeNodeID {
+	n, ok := v.node(uid)
+	if !ok {
+		return nil
+	}
+	var uids []widget.TreeNodeID
+	for _, c := range v.children(n) {
+		id := treeUID(uid, v.keyString(c.key))
+		v.nodes[id] = c
+		uids = append(uids, id)
+	}
+	return uids
+}
+
+// node returns the node of uid. Nodes are remembered when their parent's
+// children are listed, others, like those of branches that stay open after
+// the structure was replaced, are found by walking down from the root.
+func (v *treeView) node(uid widget.TreeNodeID) (*treeNode, bool) {
+	if n, ok := v.nodes[uid]; ok {
+		return n, true
+	}
+	keys, ok := treeUIDKeys(uid)
+	if !ok {
+		return nil, false
+	}
+	n := v.nodes[""]
+	for _, k := range keys {
+		var found *treeNode
+		for _, c := range v.children(n) {
+			if v.keyString(c.key) == k {
+				found = c
+				break
+			}
+		}
+		if found == nil {
+			return nil, false
+		}
+		n = found
+	}
+	v.nodes[uid] = n
+	return n, true
+}
+
+// treeUID returns the UID of the child with the key of the node with UID
+// parent.
+func treeUID(parent widget.TreeNodeID, key string) widget.TreeNodeID {
+	return parent + strconv.Itoa(len(key)) + ":" + key
+}
+
+// treeUIDKeys returns the keys on the path of a UID.
+func treeUIDKeys(uid widget.TreeNodeID) ([]string, bool) {
+	var keys []string
+	for uid != "" {
+		n, rest, ok := strings.Cut(uid, ":")
+		size, err := strconv.Atoi(n)
+		if !ok || err != nil || size < 0 || size > len(rest) {
+			return nil, false
+		}
+		keys = append(keys, rest[:size])
+		uid = rest[size:]
+	}
+	return keys, true
+}
+
+// children returns the entries of the dict or context, sorted by key, or
+// the items of the block or list in n.
+func (v *treeView) children(n *treeNode) []*treeNode {
+	var nodes []*treeNode
+	add := func(key, value env.Object) {
+		path := append(append([]env.Object(nil), n.path...), key)
+		nodes = append(nodes, &treeNode{key: key, value: value, path: path})
+	}
+	switch x := n.value.(type) {
+	case env.Dict:
+		keys := make([]string, 0, len(x.Data))
+		for k := range x.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			add(*env.NewString(k), env.ToRyeValue(x.Data[k]))
+		}
+	case env.RyeCtx:
+		v.contextChildren(&x, add)
+	case *env.RyeCtx:
+		v.contextChildren(x, add)
+	case env.Block:
+		for i, item := range x.Series.GetAll() {
+			add(*env.NewInteger(int64(i)), item)
+		}
+	case env.List:
+		for i, item := range x.Data {
+			add(*env.NewInteger(int64(i)), env.ToRyeValue(item))
+		}
+	}
+	return nodes
+}
+
+func (v *treeView) contextChildren(ctx *env.RyeCtx, add func(key, value env.Object)) {
+	state := ctx.GetState()
+	words := make([]int, 0, len(state))
+	for w := range state {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		return v.ps.Idx.GetWord(words[i]) < v.ps.Idx.GetWord(words[j])
+	})
+	for _, w := range words {
+		add(*env.NewWord(w), state[w])
+	}
+}
+
+func (v *treeView) keyString(key env.Object) string {
+	switch k := key.(type) {
+	case env.String:
+		return k.Value
+	case env.Word:
+		return v.ps.Idx.GetWord(k.Index)
+	case env.Integer:
+		return strconv.FormatInt(k.Value, 10)
+	}
+	return ""
+}
+
+// label shows the key of branches and dict and context entries, and the
+// value of leaves.
+func (v *treeView) label(n *treeNode, branch bool) string {
+	key := v.keyString(n.key)
+	if branch {
+		return key
+	}
+	value := "nil"
+	if n.value != nil {
+		value = n.value.Print(*v.ps.Idx)
+	}
+	if _, ok := n.key.(env.Integer); ok {
+		return value
+	}
+	return key + ": " + value
+}
+
+func isTreeBranch(obj env.Object) bool {
+	switch obj.(type) {
+	case env.Dict, env.RyeCtx, *env.RyeCtx, env.Block, env.List:
+		return true
+	}
+	return false
+}
+
+// treeViewFromRye returns the view of a tree created by tree-from.
+func treeViewFromRye(ps *env.ProgramState, obj env.Object) (*treeView, error) {
+	if nat, ok := obj.(env.Native); ok {
+		if t, ok := nat.Value.(*widget.Tree); ok {
+			if v, ok := treeViews.load(t); ok {
+				return v.Value(), nil
+			}
+			return nil, errors.New("expected tree created by tree-from")
+		}
+	}
+	return nil, errors.New("expected native of type go(*widget/Tree), but got " + objectType(ps, obj))
+}
+
+func init() {
+	m := builtins_fyne_widget
+	m["tree-from"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Creates a tree widget showing nested dicts, contexts, blocks and lists, or the structure held by the variable named by the word.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			if isTreeBranch(args[0]) {
+				nat, _ := autoToNative(ps, newTreeView(ps, args[0]).tree)
+				return nat
+			}
+			word, ok := args[0].(env.Word)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected dict, context, block, list or word naming a variable, but got " + objectType(ps, args[0]))
+			}
+			ctx, err := findVar(ps, word)
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			name := ps.Idx.GetWord(word.Index)
+			cur, _ := ctx.GetCurrent(word.Index)
+			if !isTreeBranch(cur) {
+				ps.FailureFlag = true
+				return env.NewError("expected variable '" + name + "' to hold a dict, context, block or list, but got " + objectType(ps, cur))
+			}
+			v := newTreeView(ps, cur)
+			// As for list-from, the observer holds the view weakly.
+			wv := weak.Make(v)
+			remove := observeVar(ctx, word.Index, func(ps *env.ProgramState, val env.Object) {
+				v := wv.Value()
+				if v == nil {
+					return
+				}
+				if !isTreeBranch(val) {
+					writeBindingError(name, errors.New("expected dict, context, block or list, but got "+objectType(ps, val)))
+					return
+				}
+				fyne.Do(func() {
+					v.setRoot(val)
+					v.tree.Refresh()
+				})
+			})
+			runtime.AddCleanup(v, func(remove func()) { remove() }, remove)
+			nat, _ := autoToNative(ps, v.tree)
+			return nat
+		},
+	}
+	m["go(*widget/Tree)//on-node-selected!"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Sets the function called with the path of keys to the selected node and its value, for a tree created by tree-from.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			v, err := treeViewFromRye(ps, args[0])
+			var fn *env.Function
+			if err == nil {
+				fn, err = selectionCallback(ps, args[1])
+			}
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			v.mu.Lock()
+			v.onSelected = fn
+			v.mu.Unlock()
+			return args[0]
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/refaktor/rye/env"
+)
+
+func TestTreeViewKeys(t *testing.T) {
+	ps := testProgramState(t)
+	root := *env.NewDict(map[string]any{
+		"":         *env.NewDict(map[string]any{"x": *env.NewInteger(1)}),
+		"a\x1fb":   *env.NewInteger(2),
+		"a":        *env.NewDict(map[string]any{"b": *env.NewInteger(3)}),
+		"1:a":      *env.NewInteger(4),
+		"nested:1": *env.NewDict(map[string]any{"": *env.NewInteger(5)}),
+	})
+	v := newTreeView(ps, root)
+
+	// The values of the leaves by their paths, found both from the UIDs
+	// listed for open branches and by walking down from the root.
+	leaves := map[string]int64{}
+	var walk func(uid string, path string)
+	walk = func(uid string, path string) {
+		for _, id := range v.childUIDs(uid) {
+			n, _ := v.node(id)
+			key := v.keyString(n.key)
+			if isTreeBranch(n.value) {
+				walk(id, path+"/"+key)
+				continue
+			}
+			leaves[path+"/"+key] = n.value.(env.Integer).Value
+			delete(v.nodes, id)
+			if m, ok := v.node(id); !ok || m.value != n.value {
+				t.Errorf("can't find %q from the root", path+"/"+key)
+			}
+		}
+	}
+	walk("", "")
+
+	want := map[string]int64{"//x": 1, "/a\x1fb": 2, "/a/b": 3, "/1:a": 4, "/nested:1/": 5}
+	if len(leaves) != len(want) {
+		t.Errorf("got leaves %q, want %q", leaves, want)
+	}
+	for path, value := range want {
+		if leaves[path] != value {
+			t.Errorf("got %d at %q, want %d", leaves[path], path, value)
+		}
+	}
+	if n, _ := v.node(""); len(n.value.(env.Dict).Data) != len(root.Data) {
+		t.Error("the root was replaced")
+	}
+}
+
+func TestTreeSelection(t *testing.T) {
+	ps := evalTest(t, testProgramState(t), `
+		import\go\all
+		picked: channel 1
+		tr: widget/tree-from dict { "a" { 1 2 } }
+		tr .on-node-selected! fn { path value } { picked .Send value }
+	`)
+	tr := testValue[*widget.Tree](t, ps, "tr")
+	tr.Select(treeUID(treeUID("", "a"), "1"))
+	if obj := *<-testValue[chan *env.Object](t, ps, "picked"); obj != *env.NewInteger(2) {
+		t.Errorf("got %s passed to on-node-selected!, want 2", objectType(ps, obj))
+	}
+	_, err := treeViewFromRye(ps, *env.NewInteger(1))
+	if err == nil || !strings.Contains(err.Error(), "go(*widget/Tree)") {
+		t.Errorf("got error %v for an integer", err)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/fsnotify/fsnotify"
+	"github.com/refaktor/rye/env"
+)
+
+// `rye-fyne -watch script.rye` runs a script and evaluates it again whenever
+// it, or another Rye file in its directory tree, changes. The app keeps
+// running: app/new returns the same app, and the windows the script opens
+// are reused in the order it opens them, so they keep their size and
+// position while the script sets their new content. If an evaluation fails,
+// the windows keep their content and show the error on top of it.
+// keep-on-reload carries values over to the next evaluation. Goroutines the
+// script started keep running.
+
+// watchMode is set by the -watch flag.
+var watchMode bool
+
+// ansiEscapes match the terminal colors of Rye's error messages.
+var ansiEscapes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// reloader is the hot reload of -watch mode, nil otherwise.
+var reloader *hotReload
+
+func init() {
+	flag.BoolVar(&watchMode, "watch", false, "Evaluate the script again when it or a Rye file next to it changes, keeping the app running")
+
+	baseBuiltins["keep-on-reload"] = &env.VarBuiltin{
+		Argsn: 2,
+		Doc:   "Returns the value a word had when -watch mode evaluated the script again, or else the default value.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			var idx int
+			switch w := args[0].(type) {
+			case env.Word:
+				idx = w.Index
+			case env.Tagword:
+				idx = w.Index
+			default:
+				ps.FailureFlag = true
+				return env.NewError("expected word, but got " + objectType(ps, args[0]))
+			}
+			if reloader != nil && reloader.last != nil {
+				if v, ok := reloader.last.Ctx.GetCurrent(idx); ok {
+					return v
+				}
+			}
+			return args[1]
+		},
+	}
+}
+
+// hotReload evaluates the file of -watch mode.
+type hotReload struct {
+	regfn func(*env.ProgramState) error
+	file  string
+	app   fyne.App
+	// windows are the windows of the last evaluation, in the order the
+	// script opened them, and opened those of the evaluation in progress.
+	windows []fyne.Window
+	opened  []fyne.Window
+	// last is the program state of the last evaluation that succeeded.
+	last        *env.ProgramState
+	errorWindow fyne.Window
+	errorPopUps []*widget.PopUp
+}
+
+// runWatch runs path in -watch mode until the app quits.
+func runWatch(regfn func(*env.ProgramState) error, path string) int {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "main.rye")
+	}
+	h := &hotReload{regfn: regfn, file: path}
+	reloader = h
+	h.useApp()
+	h.evaluate()
+	if h.app == nil {
+		// The script didn't create an app, there is nothing to keep running.
+		return 0
+	}
+	dirs, err := ryeDirs(filepath.Dir(path))
+	stop := func() {}
+	if err == nil {
+		stop, err = watchFiles(dirs, isRyeFile, func() {
+			fyne.DoAndWait(h.evaluate)
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error watching '%s': %s\n", path, err)
+		return 1
+	}
+	defer stop()
+	if replMode {
+		startRepl(func() *env.ProgramState { return h.last })
+	}
+	h.app.Run()
+	stopThemeWatches()
+	return 0
+}
+
+// useApp makes app/new return the app of the hot reload, and app windows
+// reuse the windows of the last evaluation. Running the app is left to
+// runWatch.
+func (h *hotReload) useApp() {
+	newApp := func(ps *env.ProgramState, id string) env.Object {
+		if h.app == nil {
+			if id != "" {
+				h.app = app.NewWithID(id)
+			} else {
+				h.app = app.New()
+			}
+		}
+		obj, _ := conv_fyne_io_fyne_v2_App_toRye(ps, nil, h.app)
+		return obj
+	}
+	builtins_fyne_app["new"] = &env.VarBuiltin{
+		Argsn: 0,
+		Doc:   "Returns the app kept running by -watch mode.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return newApp(ps, "")
+		},
+	}
+	builtins_fyne_app["new-with-id"] = &env.VarBuiltin{
+		Argsn: 1,
+		Doc:   "Returns the app kept running by -watch mode, created with the ID the first time.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			id, ok := args[0].(env.String)
+			if !ok {
+				ps.FailureFlag = true
+				return env.NewError("expected string, but got " + objectType(ps, args[0]))
+			}
+			return newApp(ps, id.Value)
+		},
+	}
+
+	m := builtins_fyne
+	newWindow := m["go(fyne/App)//window"]
+	m["go(fyne/App)//window"] = &env.VarBuiltin{
+		Argsn: newWindow.Argsn,
+		Doc:   newWindow.Doc,
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			if i := len(h.opened); i < len(h.windows) && h.isOpen(h.windows[i]) {
+				w := h.windows[i]
+				title, ok := args[1].(env.String)
+				if !ok {
+					ps.FailureFlag = true
+					return env.NewError("expected string, but got " + objectType(ps, args[1]))
+				}
+				w.SetTitle(title.Value)
+				h.opened = append(h.opened, w)
+				obj, _ := conv_fyne_io_fyne_v2_Window_toRye(ps, nil, w)
+				return obj
+			}
+			res := newWindow.Fn(ps, args...)
+			if nat, ok := res.(env.Native); ok {
+				if w, ok := nat.Value.(fyne.Window); ok {
+					h.opened = append(h.opened, w)
+				}
+			}
+			return res
+		},
+	}
+	showAndRun := m["go(fyne/Window)//show-and-run"]
+	m["go(fyne/Window)//show-and-run"] = &env.VarBuiltin{
+		Argsn: showAndRun.Argsn,
+		Doc:   "Shows the window, the app is run by -watch mode.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			w, err := conv_fyne_io_fyne_v2_Window_fromRye(ps, nil, args[0])
+			if err != nil {
+				ps.FailureFlag = true
+				return env.NewError(err.Error())
+			}
+			w.Show()
+			return args[0]
+		},
+	}
+	run := m["go(fyne/App)//run"]
+	m["go(fyne/App)//run"] = &env.VarBuiltin{
+		Argsn: run.Argsn,
+		Doc:   "Does nothing, the app is run by -watch mode.",
+		Fn: func(ps *env.ProgramState, args ...env.Object) env.Object {
+			return args[0]
+		},
+	}
+}
+
+// isOpen reports whether the user hasn't closed w.
+func (h *hotReload) isOpen(w fyne.Window) bool {
+	return slices.Contains(h.app.Driver().AllWindows(), w)
+}
+
+// evaluate evaluates the file in a new program state. If that fails, the
+// windows get back their content and show the error.
+func (h *hotReload) evaluate() {
+	type windowState struct {
+		title   string
+		content fyne.CanvasObject
+		size    fyne.Size
+	}
+	saved := make(map[fyne.Window]windowState, len(h.windows))
+	for _, w := range h.windows {
+		saved[w] = windowState{w.Title(), w.Content(), w.Canvas().Size()}
+	}
+	h.opened = nil
+
+	var idx *env.Idxs
+	if h.last != nil {
+		idx = h.last.Idx
+	}
+	ps, err := newProgramState(h.regfn, h.file, idx)
+	if err == nil {
+		ps, err = evalFile(ps, h.file)
+	}
+
+	if err != nil {
+		for _, w := range h.opened {
+			if s, ok := saved[w]; ok {
+				w.SetTitle(s.title)
+				w.SetContent(s.content)
+				w.Resize(s.size)
+			} else {
+				w.Close()
+			}
+		}
+		h.opened = nil
+		forgetModules(ps)
+		h.showError(err)
+		return
+	}
+	h.hideError()
+	for _, w := range h.windows {
+		if !slices.Contains(h.opened, w) {
+			w.Close()
+		}
+	}
+	for _, w := range h.opened {
+		if s, ok := saved[w]; ok {
+			w.Resize(s.size)
+		}
+	}
+	if h.errorWindow != nil && len(h.opened) > 0 {
+		h.errorWindow.Close()
+		h.errorWindow = nil
+	}
+	h.windows, h.opened = h.opened, nil
+	forgetModules(h.last)
+	h.last = ps
+}
+
+// showError reports err on stderr and over the content of the windows, or
+// in a window of its own if the script has none yet.
+func (h *hotReload) showError(err error) {
+	fmt.Fprintf(os.Stderr, "Error in '%s': %s\n", h.file, err)
+	h.hideError()
+	var windows []fyne.Window
+	for _, w := range h.windows {
+		if h.app != nil && h.isOpen(w) {
+			windows = append(windows, w)
+		}
+	}
+	if len(windows) == 0 {
+		if h.app == nil {
+			h.app = app.New()
+		}
+		if h.errorWindow == nil {
+			h.errorWindow = h.app.NewWindow(filepath.Base(h.file))
+			h.errorWindow.Resize(fyne.NewSize(480, 240))
+			h.errorWindow.Show()
+		}
+		h.errorWindow.SetContent(errorView(h.file, err, nil))
+		return
+	}
+	for _, w := range windows {
+		c := w.Canvas()
+		var pop *widget.PopUp
+		pop = widget.NewPopUp(errorView(h.file, err, func() { pop.Hide() }), c)
+		pop.ShowAtPosition(fyne.NewPos(0, 0))
+		pop.Resize(fyne.NewSize(c.Size().Width, max(c.Size().Height/3, pop.MinSize().Height)))
+		h.errorPopUps = append(h.errorPopUps, pop)
+	}
+}
+
+func (h *hotReload) hideError() {
+	for _, pop := range h.errorPopUps {
+		pop.Hide()
+	}
+	h.errorPopUps = nil
+}
+
+// errorView shows an evaluation error of file, with a button to dismiss it
+// if dismiss is not nil.
+func errorView(file string, err error, dismiss func()) fyne.CanvasObject {
+	title := widget.NewLabelWithStyle("Error in "+file, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Importance = widget.DangerImportance
+	msg := widget.NewLabel(ansiEscapes.ReplaceAllString(err.Error(), ""))
+	msg.Wrapping = fyne.TextWrapWord
+	var button fyne.CanvasObject
+	if dismiss != nil {
+		button = widget.NewButton("Dismiss", dismiss)
+	}
+	return container.NewBorder(container.NewBorder(nil, nil, nil, button, title), nil, nil, nil, container.NewVScroll(msg))
+}
+
+// isRyeFile reports whether the file name is a Rye file, the files -watch
+// mode evaluates the script again for.
+func isRyeFile(name string) bool {
+	return strings.HasSuffix(name, ".rye")
+}
+
+// ryeDirs returns dir and the directories in it, except hidden ones.
+func ryeDirs(dir string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, p)
+		return nil
+	})
+	return dirs, err
+}
+
+// watchFiles calls changed after files in dirs that match were written,
+// created or replaced, once the writes settle, until stop is called.
+func watchFiles(dirs []string, match func(name string) bool, changed func()) (stop func(), err error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	go func() {
+		var timer *time.Timer
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		for {
+			select {
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if !match(filepath.Clean(e.Name)) || !e.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(100*time.Millisecond, changed)
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(os.Stderr, "Error watching '%s': %s\n", strings.Join(dirs, "', '"), err)
+			}
+		}
+	}()
+	return func() { w.Close() }, nil
+}
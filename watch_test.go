@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/refaktor/rye/env"
+)
+
+// testHotReload returns a hot reload of file using the test app. The
+// builtins useApp replaces are restored after the test.
+func testHotReload(t *testing.T, file string) *hotReload {
+	t.Helper()
+	a := test.NewTempApp(t)
+	saved := map[*map[string]*env.VarBuiltin]map[string]*env.VarBuiltin{
+		&builtins_fyne_app: {"new": nil, "new-with-id": nil},
+		&builtins_fyne:     {"go(fyne/App)//window": nil, "go(fyne/Window)//show-and-run": nil, "go(fyne/App)//run": nil},
+	}
+	for m, names := range saved {
+		for name := range names {
+			names[name] = (*m)[name]
+		}
+	}
+	t.Cleanup(func() {
+		for m, names := range saved {
+			for name, b := range names {
+				(*m)[name] = b
+			}
+		}
+		reloader = nil
+	})
+	h := &hotReload{regfn: testRegister, file: file, app: a}
+	reloader = h
+	h.useApp()
+	return h
+}
+
+func TestHotReloadKeepsState(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.rye")
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+		import\go\all
+		runs: 1 + keep-on-reload 'runs 0
+		w: app/new |window "Counter"
+		w .set-content widget/label "Runs"
+		w .show-and-run
+	`)
+	h := testHotReload(t, file)
+	runs := func() int64 {
+		t.Helper()
+		obj, _ := h.last.Ctx.Get(h.last.Idx.IndexWord("runs"))
+		return obj.(env.Integer).Value
+	}
+
+	h.evaluate()
+	if h.last == nil || runs() != 1 || len(h.windows) != 1 {
+		t.Fatalf("the first evaluation failed")
+	}
+	w := h.windows[0]
+	w.Resize(fyne.NewSize(300, 200))
+	h.evaluate()
+	if got := runs(); got != 2 {
+		t.Errorf("got runs %d after reloading, want 2", got)
+	}
+	if len(h.windows) != 1 || h.windows[0] != w {
+		t.Error("the window wasn't reused")
+	} else if got := w.Canvas().Size(); got != fyne.NewSize(300, 200) {
+		t.Errorf("got window size %v, want the size before reloading", got)
+	}
+
+	// A failing evaluation leaves the last state to carry over.
+	last := h.last
+	write(`runs: 1 + keep-on-reload 'runs 0  fail "broken"`)
+	h.evaluate()
+	if h.last != last || len(h.errorPopUps) != 1 {
+		t.Error("a failing evaluation replaced the last state, or showed no error")
+	}
+	write(`runs: 1 + keep-on-reload 'runs 0`)
+	h.evaluate()
+	if got := runs(); got != 3 {
+		t.Errorf("got runs %d after a failed reload, want 3", got)
+	}
+}
+
+func TestWatchFilesFilter(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "lib")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dirs, err := ryeDirs(dir)
+	if err != nil || len(dirs) != 2 {
+		t.Fatalf("got dirs %q, %v, want %q and %q", dirs, err, dir, sub)
+	}
+
+	var scripts, themes atomic.Int32
+	stopScripts, err := watchFiles(dirs, isRyeFile, func() { scripts.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stopScripts()
+	theme := filepath.Join(dir, "theme.json")
+	stopTheme, err := watchFile(theme, func() { themes.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stopTheme()
+
+	write := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "notes.txt"))
+	write(filepath.Join(dir, "other.json"))
+	time.Sleep(300 * time.Millisecond)
+	if scripts.Load() != 0 || themes.Load() != 0 {
+		t.Fatalf("got %d script and %d theme changes for other files, want none", scripts.Load(), themes.Load())
+	}
+
+	write(filepath.Join(sub, "module.rye"))
+	waitFor(t, "the script change", func() bool { return scripts.Load() == 1 })
+	write(theme)
+	waitFor(t, "the theme change", func() bool { return themes.Load() == 1 })
+	time.Sleep(300 * time.Millisecond)
+	if scripts.Load() != 1 {
+		t.Errorf("got %d script changes after writing the theme, want 1", scripts.Load())
+	}
+
+	// Stopped watches don't report changes.
+	stopTheme()
+	write(theme)
+	time.Sleep(300 * time.Millisecond)
+	if themes.Load() != 1 {
+		t.Errorf("got %d theme changes after stopping, want 1", themes.Load())
+	}
+}